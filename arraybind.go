@@ -0,0 +1,98 @@
+package bulk
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// OracleArrayBind is an Oracle Backend which inserts the rows with a single-row insert statement executed
+// once with array binding: every argument is a slice holding the values of a column, as supported by
+// github.com/godror/godror. The values of a column must all have the same type. The columns with NULL
+// values are bound as slices of the matching sql.Null* type, which requires their type to have one, e.g.
+// []sql.NullString for strings. Duplicates can't be handled.
+var OracleArrayBind Backend = oracleArrayBind{}
+
+// oracleArrayBind is the Backend of OracleArrayBind.
+type oracleArrayBind struct{}
+
+// Load inserts the rows of t binding a slice per column.
+func (oracleArrayBind) Load(ctx context.Context, db Execer, t *Table) (sql.Result, error) {
+	if t.Conflict != nil {
+		return nil, fmt.Errorf("ERROR: Array binding can't handle duplicates")
+	}
+	args := make([]interface{}, len(t.Columns))
+	for i, c := range t.Columns {
+		col, err := columnSlice(t.Rows, i)
+		if err != nil {
+			return nil, fmt.Errorf("ERROR: The column %v can't be bound as an array: %w", c, err)
+		}
+		args[i] = col
+	}
+	var sb strings.Builder
+	sb.WriteString("INSERT INTO " + t.QuotedName + " (" + strings.Join(t.QuotedColumns, ", ") + ") VALUES (")
+	for i := range t.Columns {
+		if i > 0 {
+			sb.WriteByte(',')
+		}
+		sb.WriteString(":" + strconv.Itoa(i+1))
+	}
+	sb.WriteByte(')')
+	return db.ExecContext(ctx, sb.String(), args...)
+}
+
+// nullTypes are the sql.Null* types of the columns with NULL values, by the type of their other values.
+var nullTypes = map[reflect.Type]reflect.Type{
+	reflect.TypeOf(""):          reflect.TypeOf(sql.NullString{}),
+	reflect.TypeOf(0):           reflect.TypeOf(sql.NullInt64{}),
+	reflect.TypeOf(int64(0)):    reflect.TypeOf(sql.NullInt64{}),
+	reflect.TypeOf(int32(0)):    reflect.TypeOf(sql.NullInt32{}),
+	reflect.TypeOf(int16(0)):    reflect.TypeOf(sql.NullInt16{}),
+	reflect.TypeOf(float64(0)):  reflect.TypeOf(sql.NullFloat64{}),
+	reflect.TypeOf(false):       reflect.TypeOf(sql.NullBool{}),
+	reflect.TypeOf(time.Time{}): reflect.TypeOf(sql.NullTime{}),
+}
+
+// columnSlice returns the values of the column i of rows as a slice of their type, e.g. []string, or of
+// the matching sql.Null* type if there are NULL values.
+func columnSlice(rows [][]interface{}, i int) (interface{}, error) {
+	var typ reflect.Type
+	nulls := false
+	for _, row := range rows {
+		if row[i] == nil {
+			nulls = true
+			continue
+		}
+		if t := reflect.TypeOf(row[i]); typ == nil {
+			typ = t
+		} else if t != typ {
+			return nil, fmt.Errorf("the values have the types %v and %v", typ, t)
+		}
+	}
+	if typ == nil {
+		typ = reflect.TypeOf("")
+	}
+	if !nulls {
+		col := reflect.MakeSlice(reflect.SliceOf(typ), len(rows), len(rows))
+		for r, row := range rows {
+			col.Index(r).Set(reflect.ValueOf(row[i]))
+		}
+		return col.Interface(), nil
+	}
+
+	nullType, ok := nullTypes[typ]
+	if !ok {
+		return nil, fmt.Errorf("the type %v has no sql.Null* type for the NULL values", typ)
+	}
+	col := reflect.MakeSlice(reflect.SliceOf(nullType), len(rows), len(rows))
+	for r, row := range rows {
+		if err := col.Index(r).Addr().Interface().(sql.Scanner).Scan(row[i]); err != nil {
+			return nil, err
+		}
+	}
+	return col.Interface(), nil
+}
@@ -0,0 +1,59 @@
+package bulk
+
+import (
+	"database/sql"
+	"reflect"
+	"testing"
+)
+
+func TestOracleArrayBind(t *testing.T) {
+	r := NewRecorder()
+	b := New(Oracle)
+	b.Init("t", "id", "name", "score")
+	b.SetBackend(OracleArrayBind)
+	b.PrepareValues(1, "a", nil)
+	b.PrepareValues(2, nil, nil)
+	if err := b.Insert(r.DB(), false); err != nil {
+		t.Fatal(err)
+	}
+	// A single-row statement binds a slice per column, of the sql.Null* type for the columns with NULL values
+	err := r.Expect(Statement{
+		SQL: `INSERT INTO "t" ("id", "name", "score") VALUES (:1,:2,:3)`,
+		Args: []interface{}{
+			[]int{1, 2},
+			[]sql.NullString{{String: "a", Valid: true}, {}},
+			[]sql.NullString{{}, {}},
+		},
+	})
+	if err != nil {
+		t.Error(err)
+	}
+
+	b.SetConflictTarget("id")
+	checkError(t, b.Insert(r.DB(), true), "Array binding can't handle duplicates")
+}
+
+func TestColumnSlice(t *testing.T) {
+	tests := []struct {
+		name    string
+		rows    [][]interface{}
+		want    interface{}
+		wantErr string
+	}{
+		{name: "floats", rows: [][]interface{}{{1.5}, {2.5}}, want: []float64{1.5, 2.5}},
+		{name: "null ints", rows: [][]interface{}{{int64(1)}, {nil}}, want: []sql.NullInt64{{Int64: 1, Valid: true}, {}}},
+		{name: "mixed types", rows: [][]interface{}{{1}, {"a"}}, wantErr: "the values have the types int and string"},
+		{name: "no null type", rows: [][]interface{}{{uint8(1)}, {nil}}, wantErr: "the type uint8 has no sql.Null* type"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := columnSlice(tt.rows, 0)
+			if !checkError(t, err, tt.wantErr) {
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %#v, expected %#v", got, tt.want)
+			}
+		})
+	}
+}
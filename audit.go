@@ -0,0 +1,102 @@
+package bulk
+
+import (
+	"fmt"
+	"time"
+)
+
+// Audit names the audit columns appended to every row and provides their values. The columns with an
+// empty name aren't appended.
+type Audit struct {
+	CreatedAt string             // Column set to the time the row is added, e.g. "created_at"
+	UpdatedAt string             // Column set to the time the row is added, also overwritten on duplicate, e.g. "updated_at"
+	CreatedBy string             // Column set to the value returned by User, e.g. "created_by"
+	Now       func() time.Time   // Returns the time of the timestamps, time.Now by default
+	User      func() interface{} // Returns the value of CreatedBy, required if it's set
+}
+
+// SetAudit makes Init append the audit columns of a after the given columns, so PrepareValues and the
+// methods built on it receive the values of the given columns only and fill the audit ones. On duplicate,
+// CreatedAt and CreatedBy keep their current value, while UpdatedAt is always overwritten. It must be
+// called before Init. A nil a disables the audit columns. After Init, only Now and User can change: other
+// columns than the ones appended by Init make the insert fail until Init is called again, as the buffered
+// rows have no value for them.
+func (b *Bulk) SetAudit(a *Audit) {
+	if b.table != "" && !sameColumns(auditColumns(a), auditColumns(b.audit)) {
+		b.initErr = fmt.Errorf("ERROR: The audit columns must be set before Init")
+		return
+	}
+	b.audit = a
+	if b.table != "" && b.initErr == nil {
+		b.initErr = b.validateAudit()
+	}
+}
+
+// auditColumns returns the names of the audit columns of a, in the order their values are appended.
+func auditColumns(a *Audit) []string {
+	if a == nil {
+		return nil
+	}
+	var columns []string
+	for _, c := range []string{a.CreatedAt, a.UpdatedAt, a.CreatedBy} {
+		if c != "" {
+			columns = append(columns, c)
+		}
+	}
+	return columns
+}
+
+// sameColumns reports whether a and b hold the same columns in the same order.
+func sameColumns(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// validateAudit checks that the values of the audit columns can be provided.
+func (b *Bulk) validateAudit() error {
+	if b.audit != nil && b.audit.CreatedBy != "" && b.audit.User == nil {
+		return fmt.Errorf("ERROR: The audit column %v requires a User", b.audit.CreatedBy)
+	}
+	return nil
+}
+
+// appendAudit appends the values of the audit columns of a row to vals. Both timestamps get the same time.
+func (b *Bulk) appendAudit(vals []interface{}) []interface{} {
+	a := b.audit
+	if a == nil {
+		return vals
+	}
+	if a.CreatedAt != "" || a.UpdatedAt != "" {
+		now := time.Now()
+		if a.Now != nil {
+			now = a.Now()
+		}
+		if a.CreatedAt != "" {
+			vals = append(vals, now)
+		}
+		if a.UpdatedAt != "" {
+			vals = append(vals, now)
+		}
+	}
+	if a.CreatedBy != "" {
+		// Without a User, validateAudit made the insert fail: the row only keeps its width.
+		var user interface{}
+		if a.User != nil {
+			user = a.User()
+		}
+		vals = append(vals, user)
+	}
+	return vals
+}
+
+// keptOnUpdate reports whether column is an audit column which keeps its value on duplicate.
+func (b *Bulk) keptOnUpdate(column string) bool {
+	return b.audit != nil && column != "" && (column == b.audit.CreatedAt || column == b.audit.CreatedBy)
+}
@@ -0,0 +1,62 @@
+package bulk
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestAudit(t *testing.T) {
+	now := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	later := now.Add(time.Hour)
+	b := New(Postgres)
+	b.SetAudit(&Audit{CreatedAt: "created_at", UpdatedAt: "updated_at", CreatedBy: "created_by", Now: func() time.Time { return now }, User: func() interface{} { return "bob" }})
+	b.Init("t", "id")
+	b.SetConflictTarget("id")
+	b.PrepareValues(1)
+	// Now and User can change after Init
+	b.SetAudit(&Audit{CreatedAt: "created_at", UpdatedAt: "updated_at", CreatedBy: "created_by", Now: func() time.Time { return later }, User: func() interface{} { return "ann" }})
+	b.PrepareValues(2)
+	statements, err := b.BuildStatements(true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Statement{{
+		SQL:  `INSERT INTO "t"("id", "created_at", "updated_at", "created_by") VALUES ($1,$2,$3,$4),($5,$6,$7,$8) ON CONFLICT ("id") DO UPDATE SET "id"=EXCLUDED."id","updated_at"=EXCLUDED."updated_at"`,
+		Args: []interface{}{1, now, now, "bob", 2, later, later, "ann"},
+	}}
+	if !reflect.DeepEqual(statements, want) {
+		t.Errorf("got %v, expected %v", statements, want)
+	}
+}
+
+func TestAuditAfterInit(t *testing.T) {
+	tests := []struct {
+		name    string
+		before  *Audit
+		after   *Audit
+		wantErr string
+	}{
+		{name: "new columns", after: &Audit{CreatedAt: "created_at"}, wantErr: "The audit columns must be set before Init"},
+		{name: "disabled", before: &Audit{CreatedAt: "created_at"}, after: nil, wantErr: "The audit columns must be set before Init"},
+		{name: "other columns", before: &Audit{CreatedAt: "created_at"}, after: &Audit{UpdatedAt: "updated_at"}, wantErr: "The audit columns must be set before Init"},
+		{name: "missing user", before: &Audit{CreatedBy: "created_by", User: func() interface{} { return 1 }}, after: &Audit{CreatedBy: "created_by"}, wantErr: "requires a User"},
+		{name: "same columns", before: &Audit{CreatedAt: "created_at"}, after: &Audit{CreatedAt: "created_at", Now: time.Now}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := New(Postgres)
+			b.SetAudit(tt.before)
+			b.Init("t", "id")
+			b.SetAudit(tt.after)
+			b.PrepareValues(1)
+			statements, err := b.BuildStatements(false)
+			if !checkError(t, err, tt.wantErr) {
+				return
+			}
+			if n := len(statements[0].Args); n != 2 {
+				t.Errorf("got %v values for the row, expected 2", n)
+			}
+		})
+	}
+}
@@ -0,0 +1,123 @@
+package bulk
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+// Backend loads the buffered rows with a mechanism other than multi-row INSERT statements, usually a
+// bulk loading protocol of the database such as LOAD DATA or COPY.
+type Backend interface {
+	Load(ctx context.Context, db Execer, t *Table) (sql.Result, error)
+}
+
+// committedResult is the result returned with the error of a Backend which committed the first rows of
+// the table before failing, such as SpannerMutations.
+type committedResult struct {
+	sql.Result
+	rows int // Number of rows committed
+}
+
+// Table is the data given to a Backend.
+type Table struct {
+	Name          string          // Name of the table, as given to Init
+	Columns       []string        // Names of the columns, as given to Init
+	QuotedName    string          // Name of the table quoted for the dialect
+	QuotedColumns []string        // Names of the columns quoted for the dialect
+	Conflict      *Conflict       // How the rows colliding with an existing key are handled, nil for a plain insert
+	Rows          [][]interface{} // Values of each row, sharing the memory of the buffer
+}
+
+// SetBackend makes Insert and Exec load the rows with backend, in one go, instead of generating insert
+// statements. The batch limit, the parallelism and the continue on error mode don't apply to a backend.
+// A nil backend restores the insert statements.
+func (b *Bulk) SetBackend(backend Backend) {
+	b.backend = backend
+}
+
+// execBackend loads the rows with the backend, as a single batch.
+func (b *Bulk) execBackend(ctx context.Context, db Execer, replaceOnDuplicate bool, result *Result) error {
+	if err := b.ready(); err != nil {
+		return err
+	}
+	if err := b.checkNoRaw(); err != nil {
+		return err
+	}
+	if len(b.exprs) > 0 {
+		return fmt.Errorf("ERROR: The expression columns can't be loaded with a backend")
+	}
+	conflict, err := b.conflict(replaceOnDuplicate)
+	if err != nil {
+		return err
+	}
+	bt := batch{start: b.resumeRow, end: b.rows}
+	t := &Table{
+		Name:          b.table,
+		Columns:       b.columns,
+		QuotedName:    b.quote(b.table),
+		QuotedColumns: b.quoteAll(b.columns),
+		Conflict:      conflict,
+		Rows:          make([][]interface{}, 0, bt.rows()),
+	}
+	vals := b.values(bt)
+	for i := 0; i < len(vals); i += b.valuesPerRow {
+		t.Rows = append(t.Rows, vals[i:i+b.valuesPerRow:i+b.valuesPerRow])
+	}
+
+	if b.controller != nil {
+		if err := b.controller.wait(ctx); err != nil {
+			return err
+		}
+	}
+	info := BatchInfo{FirstRow: bt.start, Rows: bt.rows()}
+	if b.onBatchStart != nil {
+		b.onBatchStart(info)
+	}
+	start := time.Now()
+	ctx, span := b.startSpan(ctx, "bulk.batch", slog.String("db.collection.name", b.table), slog.Int("bulk.batch", 0), slog.Int("bulk.rows", bt.rows()))
+	var res sql.Result
+	load := func(db Execer) error {
+		if b.truncate {
+			if err := b.clearTable(ctx, db); err != nil {
+				return err
+			}
+		}
+		return b.retry(ctx, func() error {
+			res, err = b.backend.Load(ctx, db, t)
+			return err
+		})
+	}
+	if b.inTx() {
+		err = b.withTx(ctx, db, load)
+	} else {
+		err = load(db)
+	}
+
+	b.logBatch(ctx, bt, "", time.Since(start), err)
+	if err == nil {
+		b.stats.add(BatchStats{Rows: bt.rows(), Duration: time.Since(start)})
+		if b.controller != nil {
+			b.controller.done(bt.rows())
+		}
+		if err = result.add(res); err == nil {
+			result.Committed = bt.end
+		}
+	} else {
+		// Outside of a transaction, the rows committed by the backend before failing are kept
+		if c, ok := res.(*committedResult); ok && !b.inTx() && result.add(c) == nil {
+			result.Committed = bt.start + c.rows
+		}
+		err = &BatchError{FirstRow: bt.start, Rows: bt.rows(), Err: err}
+	}
+	span.End(err)
+	b.observeBatch(bt.rows(), time.Since(start), err)
+	if b.onBatchDone != nil {
+		info.Duration = time.Since(start)
+		info.Err = err
+		b.onBatchDone(info)
+	}
+	return err
+}
@@ -0,0 +1,126 @@
+package bulk
+
+import (
+	"strings"
+
+	"github.com/daniloor/helper"
+)
+
+// batch is a range of rows which are inserted with a single statement. It always contains whole rows.
+type batch struct {
+	index int // Index of the batch in the load, starting at 0
+	start int // Index of the first row of the batch
+	end   int // Index after the last row of the batch
+}
+
+// rows returns the number of rows in the batch.
+func (bt batch) rows() int {
+	return bt.end - bt.start
+}
+
+// batches splits the rows into batches of at most limit values, skipping the rows before the resume row.
+// The split always falls on a row boundary, so every batch but the last one contains exactly
+// limit / valuesPerRow rows.
+func (b *Bulk) batches(limit int) []batch {
+	if b.resumeRow >= b.rows {
+		return nil
+	}
+	rowsPerBatch := limit / b.valuesPerRow
+	n := helper.RoundUp(float64(b.rows-b.resumeRow) / float64(rowsPerBatch))
+	batches := make([]batch, 0, n)
+	for start := b.resumeRow; start < b.rows; start += rowsPerBatch {
+		end := start + rowsPerBatch
+		if end > b.rows {
+			end = b.rows
+		}
+		batches = append(batches, batch{index: len(batches), start: start, end: end})
+	}
+	return batches
+}
+
+// values returns the values of the rows in bt.
+func (b *Bulk) values(bt batch) []interface{} {
+	return b.vals[bt.start*b.valuesPerRow : bt.end*b.valuesPerRow]
+}
+
+// writePlaceholders writes to sb the VALUES list of a statement inserting the rows in bt. The
+// placeholders are numbered from 1, as each batch is a statement on its own.
+func (b *Bulk) writePlaceholders(sb *strings.Builder, bt batch) {
+	b.writeRows(sb, bt, "", b.placeholderWriter(sb))
+}
+
+// placeholderWriter returns the function writing to sb the placeholder of a value of the rows, numbering
+// them from 1. The Raw values are written instead of their placeholder.
+func (b *Bulk) placeholderWriter(sb *strings.Builder) func(row, col int) error {
+	n := 0
+	exprs := b.exprParts()
+	return func(row, col int) error {
+		if b.rawValues {
+			if raw, ok := b.vals[row*b.valuesPerRow+col].(Raw); ok {
+				sb.WriteString(string(raw))
+				return nil
+			}
+		}
+		if exprs != nil && exprs[col] != nil {
+			n = b.writeExpr(sb, exprs[col], n)
+			return nil
+		}
+		n++
+		sb.WriteString(b.dialect.Placeholder(n))
+		return nil
+	}
+}
+
+// writeRows writes to sb the rows in bt, with value writing each of their values: as a VALUES list, or
+// selected from source, joined with UNION ALL, if it isn't empty.
+func (b *Bulk) writeRows(sb *strings.Builder, bt batch, source string, value func(row, col int) error) error {
+	for row := bt.start; row < bt.end; row++ {
+		switch {
+		case source != "" && row > bt.start:
+			sb.WriteString(" UNION ALL SELECT ")
+		case source != "":
+			sb.WriteString("SELECT ")
+		case row > bt.start:
+			sb.WriteString(",(")
+		default:
+			sb.WriteByte('(')
+		}
+		for col := 0; col < b.valuesPerRow; col++ {
+			if col > 0 {
+				sb.WriteByte(',')
+			}
+			if err := value(row, col); err != nil {
+				return err
+			}
+			// The first selected row names the columns
+			if source != "" && row == bt.start {
+				sb.WriteString(" " + b.quote(b.columns[col]))
+			}
+		}
+		if source != "" {
+			sb.WriteString(" FROM " + source)
+		} else {
+			sb.WriteByte(')')
+		}
+	}
+	return nil
+}
+
+// rowSource returns the table the rows of the statements are selected from if the dialect is a
+// RowSelector, "" otherwise.
+func (b *Bulk) rowSource() string {
+	if s, ok := b.dialect.(RowSelector); ok {
+		return s.RowSource()
+	}
+	return ""
+}
+
+// placeholdersLen estimates the length of the VALUES list of bt, so the builder grows only once.
+func (b *Bulk) placeholdersLen(bt batch) int {
+	n := bt.rows() * b.valuesPerRow
+	size := n*(len(b.dialect.Placeholder(n))+1) + bt.rows()*2
+	if source := b.rowSource(); source != "" {
+		size += bt.rows() * len(" UNION ALL SELECT  FROM "+source)
+	}
+	return size
+}
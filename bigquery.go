@@ -0,0 +1,85 @@
+package bulk
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+)
+
+// BigQueryMaxRows is the number of rows BigQuery recommends to send in a single streaming insert request.
+const BigQueryMaxRows = 500
+
+// BigQueryInserter streams rows into a BigQuery table, each given as a map from the column names to their
+// values, usually with an append stream of the Storage Write API or with the *bigquery.Inserter of
+// cloud.google.com/go/bigquery. It isn't implemented here, so this package doesn't depend on the BigQuery
+// client. A *bigquery.Inserter doesn't satisfy it, as its Put takes ValueSavers, but an adapter does, e.g.:
+//
+//	type row map[string]interface{}
+//
+//	func (r row) Save() (map[string]bigquery.Value, string, error) {
+//		m := make(map[string]bigquery.Value, len(r))
+//		for k, v := range r {
+//			m[k] = v
+//		}
+//		return m, bigquery.NoDedupeID, nil
+//	}
+//
+//	type inserter struct{ *bigquery.Inserter }
+//
+//	func (ins inserter) Put(ctx context.Context, rows []map[string]interface{}) error {
+//		savers := make([]bigquery.ValueSaver, len(rows))
+//		for i, r := range rows {
+//			savers[i] = row(r)
+//		}
+//		return ins.Inserter.Put(ctx, savers)
+//	}
+type BigQueryInserter interface {
+	Put(ctx context.Context, rows []map[string]interface{}) error
+}
+
+// BigQueryInsert returns a Backend which streams the rows to BigQuery with ins, so the same Bulk can load
+// MySQL or BigQuery. The rows are sent in several requests, each with at most maxRows rows,
+// BigQueryMaxRows if it's 0, which must also stay under the 10 MB limit of a request. If a request fails,
+// the previous ones are kept, and Result.Committed tells the row from which the load can be resumed. The table the rows are written to is the one of ins, and the db given to
+// Insert isn't used, so it can be nil. SetAtomic, SetCommitEvery and SetTruncate aren't supported, and
+// duplicates can't be handled, as the streamed rows are only appended.
+func BigQueryInsert(ins BigQueryInserter, maxRows int) Backend {
+	if maxRows <= 0 {
+		maxRows = BigQueryMaxRows
+	}
+	return &bigQueryInsert{inserter: ins, max: maxRows}
+}
+
+// bigQueryInsert is the Backend of BigQueryInsert.
+type bigQueryInsert struct {
+	inserter BigQueryInserter
+	max      int
+}
+
+// Load streams the rows of t, up to max at a time.
+func (q *bigQueryInsert) Load(ctx context.Context, _ Execer, t *Table) (sql.Result, error) {
+	if t.Conflict != nil {
+		return nil, fmt.Errorf("ERROR: BigQuery streaming inserts can't handle duplicates")
+	}
+	var sent int64
+	for start := 0; start < len(t.Rows); start += q.max {
+		end := start + q.max
+		if end > len(t.Rows) {
+			end = len(t.Rows)
+		}
+		rows := make([]map[string]interface{}, 0, end-start)
+		for _, row := range t.Rows[start:end] {
+			m := make(map[string]interface{}, len(t.Columns))
+			for i, c := range t.Columns {
+				m[c] = row[i]
+			}
+			rows = append(rows, m)
+		}
+		if err := q.inserter.Put(ctx, rows); err != nil {
+			return &committedResult{Result: driver.RowsAffected(sent), rows: int(sent)}, err
+		}
+		sent += int64(end - start)
+	}
+	return driver.RowsAffected(sent), nil
+}
@@ -0,0 +1,68 @@
+package bulk
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// rowsInserter is a BigQueryInserter recording the rows of every request. The request number fail fails,
+// counting from 1.
+type rowsInserter struct {
+	requests [][]map[string]interface{}
+	fail     int
+}
+
+func (ins *rowsInserter) Put(ctx context.Context, rows []map[string]interface{}) error {
+	if len(ins.requests)+1 == ins.fail {
+		return errors.New("quota exceeded")
+	}
+	ins.requests = append(ins.requests, rows)
+	return nil
+}
+
+func TestBigQueryInsert(t *testing.T) {
+	ins := &rowsInserter{}
+	b := New(MySQL)
+	b.Init("t", "id", "name")
+	b.SetBackend(BigQueryInsert(ins, 2))
+	for i := 1; i <= 3; i++ {
+		b.PrepareValues(i, "n")
+	}
+	result, err := b.Exec(context.Background(), nil, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := [][]map[string]interface{}{
+		{{"id": 1, "name": "n"}, {"id": 2, "name": "n"}},
+		{{"id": 3, "name": "n"}},
+	}
+	if !reflect.DeepEqual(ins.requests, want) {
+		t.Errorf("got %v, expected %v", ins.requests, want)
+	}
+	if result.RowsAffected != 3 || result.Committed != 3 {
+		t.Errorf("got %+v", result)
+	}
+
+	_, err = b.Exec(context.Background(), nil, true)
+	checkError(t, err, "BigQuery streaming inserts can't handle duplicates")
+}
+
+func TestBigQueryInsertFailure(t *testing.T) {
+	ins := &rowsInserter{fail: 2}
+	b := New(MySQL)
+	b.Init("t", "id")
+	b.SetBackend(BigQueryInsert(ins, 2))
+	for i := 1; i <= 3; i++ {
+		b.PrepareValues(i)
+	}
+	// The rows of the first request are kept
+	result, err := b.Exec(context.Background(), nil, false)
+	if err == nil {
+		t.Fatal("expected the error of the second request")
+	}
+	if result.Committed != 2 || result.RowsAffected != 2 {
+		t.Errorf("got %+v, expected the 2 rows of the first request", result)
+	}
+}
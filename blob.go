@@ -0,0 +1,64 @@
+package bulk
+
+import (
+	"fmt"
+	"io"
+)
+
+// DefaultMaxBlobSize is the maximum number of bytes read from an io.Reader value unless SetMaxBlobSize is
+// set.
+const DefaultMaxBlobSize = 16 << 20
+
+// SetMaxBlobSize limits the number of bytes read from each io.Reader value to n. A value of 0 uses
+// DefaultMaxBlobSize, and a negative value disables the limit.
+//
+// The io.Reader values, e.g. an *os.File, are sent as the bytes they hold, for the BLOB, BYTEA and
+// VARBINARY columns. They are read when the statements are built, so the buffer doesn't hold the blobs
+// until then, and closed once read if they are an io.Closer. A reader holding more than the limit makes
+// the insert fail. The blobs count their size for SetMaxBytes, which should be set with MySQL so the
+// batches stay below max_allowed_packet, see MaxAllowedPacket.
+func (b *Bulk) SetMaxBlobSize(n int64) {
+	b.maxBlobSize = n
+}
+
+// readBlobs replaces the io.Reader values of the buffered rows with the bytes they hold.
+func (b *Bulk) readBlobs() error {
+	if !b.blobs {
+		return nil
+	}
+	limit := b.maxBlobSize
+	if limit == 0 {
+		limit = DefaultMaxBlobSize
+	}
+	for i, v := range b.vals {
+		r, ok := v.(io.Reader)
+		if !ok {
+			continue
+		}
+		data, err := readBlob(r, limit)
+		if err != nil {
+			return fmt.Errorf("ERROR: Can't read the value of the column %v of the row %v: %v", b.columns[i%b.valuesPerRow], i/b.valuesPerRow, err)
+		}
+		b.vals[i] = data
+	}
+	b.blobs = false
+	return nil
+}
+
+// readBlob reads r up to limit bytes, or without limit if it's negative, and closes it.
+func readBlob(r io.Reader, limit int64) ([]byte, error) {
+	if c, ok := r.(io.Closer); ok {
+		defer c.Close()
+	}
+	if limit < 0 {
+		return io.ReadAll(r)
+	}
+	data, err := io.ReadAll(io.LimitReader(r, limit+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > limit {
+		return nil, fmt.Errorf("the blob exceeds %v bytes", limit)
+	}
+	return data, nil
+}
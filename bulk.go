@@ -1,130 +1,658 @@
 package bulk
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
+	"log/slog"
 	"strings"
-
-	"github.com/daniloor/helper"
+	"time"
 )
 
 const PLACEHOLDER_LIMIT = 60000
 
+// Execer is the database handle used to run the insert statements. It's satisfied by *sql.DB, *sql.Tx
+// and *sql.Conn, so the insert can be part of a larger transaction or pinned to a single connection.
+type Execer interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	PrepareContext(ctx context.Context, query string) (*sql.Stmt, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+}
+
 // This structure acts as a "Bulk Insert", which is defined as a process or method provided
 // by a database management system to load multiple rows of data into a database table.
 type Bulk struct {
-	initStr        string // Contains the first part of the string in the insert statment
-	placeholderStr string // Contains the placeholders str, which depends of the number of columns and values to insert.
-	// In the case of 2 different columns and 3 values, placeolderStr will be: (?,?),(?,?),(?,?),
-	vals []interface{} // Contains all the values to insert. It's neccesary to use the Go Interface type to manipulate both
+	table   string        // Name of the table, as given to Init
+	columns []string      // Names of the columns, as given to Init followed by the appended ones
+	initErr error         // Error found validating the table and columns of Init
+	vals    []interface{} // Contains all the values to insert. It's neccesary to use the Go Interface type to manipulate both
 	// float64 and int values
-	rows                 int    // Number of rows
-	valuesPerRow         int    // Number of values per row
-	placeholderStrHelper string // Contains a string helper which helps to construct the placeholderStr. In the case of
-	// 2 different columns, placeholderStrHelper will be: (?,?),
+	rows             int                    // Number of rows
+	valuesPerRow     int                    // Number of values per row
+	dialect          Dialect                // SQL flavour of the target database
+	conflictTarget   []string               // Columns of the unique key targeted by ON CONFLICT or matched by MERGE
+	updateColumns    []string               // Columns updated on duplicate set with OnDuplicateUpdate, all of them if empty
+	updateExprs      map[string]string      // Columns updated on duplicate with a SQL expression
+	ignoreDuplicates bool                   // Skip the rows which collide with an existing key
+	replaceInto      bool                   // Generate REPLACE INTO statements
+	retryPolicy      *RetryPolicy           // Retries of the failed batches, nil if disabled
+	atomic           bool                   // Run all the batches inside a single transaction
+	commitEvery      int                    // Number of batches committed together, 0 if not set
+	resumeRow        int                    // Index of the first row inserted, set with SetResumeRow
+	parallelism      int                    // Number of batches executed at the same time, 0 or 1 means sequentially
+	onBatchStart     func(BatchInfo)        // Hook called before executing each batch
+	onBatchDone      func(BatchInfo)        // Hook called after executing each batch
+	stats            *statsRecorder         // Statistics of the last insert
+	logger           *slog.Logger           // Logger of the executed batches, nil if disabled
+	continueOnError  bool                   // Find and skip the failing rows instead of failing the batch
+	flushStmts       *stmtCache             // Statements reused by every automatic flush until Close
+	batchLimit       int                    // Maximum number of values per statement set with SetBatchLimit, 0 if not set
+	autoFlush        *autoFlush             // Streaming mode configuration, nil if disabled
+	rawIdentifiers   bool                   // Write the table and column names without quoting them
+	backend          Backend                // Loads the rows instead of the insert statements, nil if not set
+	mapDefaults      map[string]interface{} // Values of the columns missing from the maps of AddMap
+	missingAsNull    bool                   // Insert NULL in the columns missing from the maps of AddMap
+	interval         *intervalFlusher       // Periodic flushes set with SetFlushInterval, nil if disabled
+	truncate         bool                   // Delete every row of the table before inserting, in the same transaction
+	encoders         map[string]Encoder     // Encoders of the values of each column set with SetEncoder
+	columnEncoders   []Encoder              // Encoder of each column by position, built from encoders
+	nullIfZero       []string               // Columns whose zero values are inserted as NULL
+	columnNullIfZero []bool                 // Whether each column by position is in nullIfZero
+	rawValues        bool                   // Whether some buffered value may be Raw
+	audit            *Audit                 // Audit columns appended by Init, nil if disabled
+	constants        []constantColumn       // Columns set to the same value in every row, appended by Init
+	appended         int                    // Number of columns appended after the ones given to Init
+	rowHooks         []RowHook              // Hooks called with the values of each row, added with BeforeRow
+	received         int                    // Number of rows received since Init or Reset, including the skipped ones
+	rejected         []*RowError            // Rows rejected by the hooks or the validation since Init or Reset
+	validators       map[string]Validator   // Validators of the values of each column set with SetValidator
+	columnValidators []Validator            // Validator of each column given to Init by position, built from validators
+	rowValidator     RowValidator           // Validator of the whole rows set with SetRowValidator
+	lenient          bool                   // Skip and report the invalid rows instead of failing
+	dedupeColumns    []string               // Key of the rows deduplicated before executing, nil if disabled
+	dedupePolicy     DedupePolicy           // Row kept among the ones with the same key
+	sortColumns      []string               // Columns the rows are sorted by before executing, nil if disabled
+	maxBytes         int                    // Maximum estimated size of a batch set with SetMaxBytes, 0 if not set
+	interpolate      bool                   // Write the values into the statements instead of binding them
+	sessionSetup     []string               // Statements run on the connection before the load
+	sessionTeardown  []string               // Statements run on the connection after the load, restoring the session
+	disableKeys      bool                   // Disable the nonunique indexes of the table during the load
+	dropIndexes      []string               // Statements dropping indexes before the load
+	createIndexes    []string               // Statements creating the indexes again after the load
+	tracer           Tracer                 // Starts the spans of the inserts and batches, nil if disabled
+	metrics          Metrics                // Receives the outcome of every batch, nil if disabled
+	comment          string                 // Comment of the statements set with SetComment, empty if disabled
+	commentFirst     bool                   // Prepend the comment instead of appending it
+	limiter          Limiter                // Throttles the batches, nil if disabled
+	limitRows        bool                   // Wait for an event per row instead of per batch
+	controller       *Controller            // Pauses the batches and receives their progress, nil if not set
+	checkpoint       *checkpoint            // Store of the committed rows set with SetCheckpoint, nil if disabled
+	checkpointSkip   int                    // Number of received rows committed by a previous run, which are skipped
+	sparse           bool                   // Whether some buffered value may be Missing
+	hints            string                 // Optimizer hints comment written after the first keyword, empty if disabled
+	maxTxRows        int                    // Maximum number of rows of a transaction set with SetMaxTxRows, 0 if not set
+	exprs            map[string]string      // SQL expressions of the VALUES entries of each column set with SetExpression
+	columnExprs      [][]string             // Parts of the expression of each column by position, built from exprs
+	uuids            map[string]uuidColumn  // UUID handling of the columns set with SetUUID
+	columnUUIDs      []*uuidColumn          // UUID handling of each column by position, built from uuids
+	timeZones        map[string]timeZone    // Conversions of the times set with SetTimeZone, "" for every column
+	columnTimeZones  []*timeZone            // Conversion of the times of each column by position, built from timeZones
+	maxBlobSize      int64                  // Maximum size of an io.Reader value set with SetMaxBlobSize, 0 if not set
+	blobs            bool                   // Whether some buffered value may be an io.Reader
+	guards           map[string]StringGuard // Guards of the strings set with SetStringGuard, "" for every column
+	columnGuards     []*StringGuard         // Guard of the strings of each column by position, built from guards
+	deadLetter       DeadLetterSink         // Receives the rows which weren't inserted, nil if disabled
+	deadLettered     int                    // Number of rejected rows already given to deadLetter
+	quarantine       *quarantine            // Table receiving the rows failing in the database, nil if disabled
+	cte              *CTE                   // Common table expression wrapping the rows set with SetCTE, nil if disabled
+	conflictWhere    string                 // Predicate of the partial index targeted on conflict, empty if not set
+	updateWhere      string                 // Condition of the updates on duplicate, empty to always update
+}
+
+// New returns a Bulk which generates statements for the given dialect, e.g. bulk.New(bulk.Postgres).
+// A zero Bulk uses the MySQL dialect.
+func New(d Dialect) *Bulk {
+	return &Bulk{dialect: d}
 }
 
-// Init initializes the attributes members
+// Init initializes the attributes members. The table and column names are validated and quoted for the
+// dialect (see SetQuoteIdentifiers); if a name is invalid, the error is returned by Insert.
 func (b *Bulk) Init(tableName string, s ...string) {
-	b.initStr = "INSERT INTO " + tableName + "(" + strings.Join(s, ", ") + ") VALUES "
+	if b.dialect == nil {
+		b.dialect = MySQL
+	}
+	b.table = tableName
+	b.columns = append([]string(nil), s...)
+	b.initErr = validateIdentifier(tableName)
+	if b.initErr == nil {
+		b.initErr = validateIdentifiers(s)
+	}
+	if len(s) == 0 {
+		b.initErr = fmt.Errorf("ERROR: Init requires at least one column")
+	}
+	appended := b.appendedColumns()
+	if b.initErr == nil {
+		b.initErr = validateAppended(s, appended)
+	}
+	if b.initErr == nil {
+		b.initErr = b.validateAudit()
+	}
+	b.columns = append(b.columns, appended...)
+	b.appended = len(appended)
 	b.vals = []interface{}{}
-	b.valuesPerRow = len(s)
+	b.valuesPerRow = len(b.columns)
+	b.columnEncoders = nil
+	b.columnNullIfZero = nil
+	b.columnValidators = nil
+	b.columnExprs = nil
+	b.columnUUIDs = nil
+	b.columnTimeZones = nil
+	b.columnGuards = nil
 	b.rows = 0
-	b.placeholderStrHelper = "(?" + strings.Repeat(",?", b.valuesPerRow-1) + "),"
+	b.received, b.rejected, b.deadLettered = 0, nil, 0
+	if b.stats == nil {
+		b.stats = &statsRecorder{}
+	}
+}
+
+// Columns returns the columns of the insert statement, as given to Init followed by the constant columns
+// and the audit columns.
+func (b *Bulk) Columns() []string {
+	return append([]string(nil), b.columns...)
+}
+
+// Rows returns the number of buffered rows, including the ones before the resume row.
+func (b *Bulk) Rows() int {
+	return b.rows
+}
+
+// Params returns the number of buffered values, including the ones of the appended columns.
+func (b *Bulk) Params() int {
+	return len(b.vals)
+}
+
+// EstimatedBytes estimates the size of the buffered values as SetMaxBytes does, without the statement
+// text. It walks the whole buffer, so it shouldn't be called after every row of a large load.
+func (b *Bulk) EstimatedBytes() int {
+	size := 0
+	for _, v := range b.vals {
+		size += valueSize(v)
+	}
+	return size
+}
+
+// Batches returns the number of statements a plain Insert of the buffered rows would execute, or 0 if
+// they can't be inserted.
+func (b *Bulk) Batches() int {
+	_, _, batches, err := b.plan(false)
+	if err != nil {
+		return 0
+	}
+	return len(batches)
+}
+
+// inputColumns returns the columns whose values are given to PrepareValues, the ones given to Init.
+func (b *Bulk) inputColumns() []string {
+	return b.columns[:len(b.columns)-b.appended]
+}
+
+// SetBatchLimit sets the maximum number of values (placeholders) sent in a single statement, e.g. 999
+// for SQLite or 2100 for SQL Server. It overrides both PLACEHOLDER_LIMIT and the MaxParameters of the
+// dialect. A value of 0 restores the default.
+func (b *Bulk) SetBatchLimit(n int) {
+	b.batchLimit = n
+}
+
+// batchSize returns the maximum number of values sent in a single statement.
+func (b *Bulk) batchSize() int {
+	size := PLACEHOLDER_LIMIT
+	if b.batchLimit > 0 {
+		size = b.batchLimit
+	} else if m := b.dialect.MaxParameters(); m < size {
+		// The dialect may allow less parameters per statement than PLACEHOLDER_LIMIT
+		size = m
+	}
+	if l, ok := b.dialect.(RowLimiter); ok && b.valuesPerRow > 0 {
+		if m := l.MaxRows() * b.valuesPerRow; m < size {
+			size = m
+		}
+	}
+	return size
 }
 
 // Insert inserts the data into the db database. If replaceOnDuplicate is true, the insert statment
-// will include a ON DUPLICATE KEY UPDATE at the end.
-func (b *Bulk) Insert(db *sql.DB, replaceOnDuplicate bool) error {
-
-	// if len(b.vals) < PLACEHOLDER_LIMIT, that means that there is no placeholder problem
-	if len(b.vals) < PLACEHOLDER_LIMIT {
-		// Trim the last ,
-		b.placeholderStr = b.placeholderStr[0 : len(b.placeholderStr)-1]
-
-		// Generate the strim that it's going to be used for the prepared statement
-		str := b.initStr + b.placeholderStr
-		if replaceOnDuplicate {
-			firstIndex := strings.Index(b.initStr, "(")
-			secondIndex := strings.Index(b.initStr, ")")
-			columnsStr := b.initStr[firstIndex+1 : secondIndex]
-			columns := strings.Split(columnsStr, ",")
-			endStr := " ON DUPLICATE KEY UPDATE "
-			for _, v := range columns {
-				endStr += v + "=VALUES(" + v + "),"
+// will include a ON DUPLICATE KEY UPDATE at the end (ON CONFLICT ... DO UPDATE for Postgres). When db
+// is a *sql.Tx, committing or rolling back the inserted rows is left to the caller. It returns ErrNoRows
+// if no rows were prepared, and a *BatchError wrapping the driver error if a statement fails.
+func (b *Bulk) Insert(db Execer, replaceOnDuplicate bool) error {
+	return b.InsertContext(context.Background(), db, replaceOnDuplicate)
+}
+
+// InsertContext works like Insert, but the statements are prepared and executed with ctx, so a long
+// multi-batch insert can be cancelled or timed out. Batches that were already executed are not rolled back,
+// unless SetAtomic was enabled.
+func (b *Bulk) InsertContext(ctx context.Context, db Execer, replaceOnDuplicate bool) error {
+	_, err := b.Exec(ctx, db, replaceOnDuplicate)
+	return err
+}
+
+// Exec works like InsertContext and also returns a summary of the executed statements, so callers can
+// verify how many rows were written or updated. If a batch fails, the returned Result covers the
+// batches which were committed before it. A db embedding a *sql.Tx, such as a *sqlx.Tx, is handled as
+// that transaction.
+func (b *Bulk) Exec(ctx context.Context, db Execer, replaceOnDuplicate bool) (*Result, error) {
+	db = sqlTx(db)
+	if setup, teardown, err := b.aroundStatements(); err != nil || len(setup)+len(teardown) > 0 {
+		if err != nil {
+			return &Result{Committed: b.resumeRow}, err
+		}
+		return b.execSession(ctx, db, setup, teardown, replaceOnDuplicate)
+	}
+	stmts := newStmtCache(db)
+	defer stmts.close()
+	return b.execWith(ctx, stmts, replaceOnDuplicate)
+}
+
+// execWith inserts the data preparing the statements with stmts, inside the span of the insert.
+func (b *Bulk) execWith(ctx context.Context, stmts *stmtCache, replaceOnDuplicate bool) (*Result, error) {
+	ctx, span := b.startSpan(ctx, "bulk.insert", slog.String("db.collection.name", b.table), slog.Int("bulk.rows", b.rows-b.resumeRow))
+	result, err := b.execLoad(ctx, stmts, replaceOnDuplicate)
+	if err == nil {
+		err = b.saveCheckpoint(ctx)
+	}
+	err = b.quarantineRows(ctx, stmts.db, result, err)
+	err = b.sendDeadLetters(ctx, result, err)
+	span.SetAttributes(slog.Int("bulk.batches", result.Batches), slog.Int64("bulk.rows_affected", result.RowsAffected))
+	span.End(err)
+	return result, err
+}
+
+// execLoad inserts the data preparing the statements with stmts. The statements prepared inside a
+// transaction are bound to it, so each transaction uses its own cache which is closed when it ends.
+func (b *Bulk) execLoad(ctx context.Context, stmts *stmtCache, replaceOnDuplicate bool) (*Result, error) {
+	db := stmts.db
+	result := &Result{Committed: b.resumeRow}
+	if b.stats == nil {
+		b.stats = &statsRecorder{}
+	}
+	b.stats.reset()
+	defer b.stats.finish(time.Now())
+
+	if err := b.readBlobs(); err != nil {
+		return result, err
+	}
+	removed, err := b.dedupe()
+	if err != nil {
+		return result, err
+	}
+	result.Deduplicated = removed
+	if err := b.sortRows(); err != nil {
+		return result, err
+	}
+	if b.sparse {
+		return result, b.execSparse(ctx, stmts, replaceOnDuplicate, result)
+	}
+	if b.backend != nil {
+		return result, b.execBackend(ctx, db, replaceOnDuplicate, result)
+	}
+	head, endStr, batches, err := b.plan(replaceOnDuplicate)
+	if err != nil {
+		return result, err
+	}
+	if b.parallelism > 1 && !b.inTx() {
+		return result, b.execParallel(ctx, stmts, batches, head, endStr, result)
+	}
+
+	// Each batch is inserted with its own statement, so the number of values in a statement never
+	// exceeds the limit. The batches of a group are committed together
+	for _, group := range b.txGroups(batches) {
+		if b.inTx() {
+			saved := *result
+			err := b.withTx(ctx, db, func(tx Execer) error {
+				// A restarted transaction inserts the group again
+				*result = saved
+				if b.truncate {
+					if err := b.clearTable(ctx, tx); err != nil {
+						return err
+					}
+				}
+				txStmts := newStmtCache(tx)
+				defer txStmts.close()
+				return b.execBatches(ctx, txStmts, group, head, endStr, result)
+			})
+			if err != nil {
+				// The batches of the group were rolled back
+				*result = saved
+				return result, err
 			}
-			endStr = endStr[:len(endStr)-1]
-			str += endStr
+		} else if err := b.execBatches(ctx, stmts, group, head, endStr, result); err != nil {
+			return result, err
 		}
-		// Prepare the statement
-		stmt, err := db.Prepare(str)
+		result.Committed = group[len(group)-1].end
+	}
+	return result, nil
+}
+
+// plan returns the start of the insert statements and their clause, and the batches they insert.
+func (b *Bulk) plan(replaceOnDuplicate bool) (head, endStr string, batches []batch, err error) {
+	if err := b.ready(); err != nil {
+		return "", "", nil, err
+	}
+	if err := b.readBlobs(); err != nil {
+		return "", "", nil, err
+	}
+	if b.sparse {
+		return "", "", nil, fmt.Errorf("ERROR: The rows with Missing values can only be inserted by Insert and Exec")
+	}
+	head, endStr, err = b.conflictClause(replaceOnDuplicate)
+	if err != nil {
+		return "", "", nil, err
+	}
+	head = b.withHints(head)
+	if head, endStr, err = b.withCTE(head, endStr); err != nil {
+		return "", "", nil, err
+	}
+	head, endStr = b.withComment(head, endStr)
+	limit := b.batchSize()
+	if p := b.paramsPerRow(); p > b.valuesPerRow {
+		// The expressions bind more parameters than values
+		limit = limit / p * b.valuesPerRow
+	}
+	if limit < b.valuesPerRow {
+		return "", "", nil, fmt.Errorf("ERROR: The batch limit (%v) is lower than the number of values per row (%v)", limit, b.valuesPerRow)
+	}
+	if b.maxBytes > 0 {
+		return head, endStr, b.sizedBatches(limit, len(head)+len(endStr)), nil
+	}
+	return head, endStr, b.batches(limit), nil
+}
+
+// ready reports why the buffered rows can't be inserted, if they can't.
+func (b *Bulk) ready() error {
+	if b.initErr != nil {
+		return b.initErr
+	}
+	if b.table == "" {
+		return ErrNotInitialized
+	}
+	if b.rows == 0 {
+		return ErrNoRows
+	}
+	return nil
+}
+
+// execBatches inserts the rows of batches with the statements of stmts, adding the results to result.
+func (b *Bulk) execBatches(ctx context.Context, stmts *stmtCache, batches []batch, head, endStr string, result *Result) error {
+	for _, bt := range batches {
+		res, err := b.execBatch(ctx, stmts, bt, head, endStr)
 		if err != nil {
 			return err
 		}
-		// Format all vals at once
-		_, err = stmt.Exec(b.vals...)
+		if err := result.add(res); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// execBatch inserts the rows of bt with a statement of stmts, retrying it as the retry policy says.
+func (b *Bulk) execBatch(ctx context.Context, stmts *stmtCache, bt batch, head, endStr string) (sql.Result, error) {
+	if err := b.wait(ctx, bt); err != nil {
+		return nil, err
+	}
+	info := BatchInfo{Index: bt.index, FirstRow: bt.start, Rows: bt.rows()}
+	if b.onBatchStart != nil {
+		b.onBatchStart(info)
+	}
+	start := time.Now()
+	ctx, span := b.startSpan(ctx, "bulk.batch", slog.String("db.collection.name", b.table), slog.Int("bulk.batch", bt.index), slog.Int("bulk.rows", bt.rows()))
+
+	// Generate the strim that it's going to be used for the prepared statement
+	var res sql.Result
+	str, args, err := b.batchStatement(head, bt, endStr)
+	if err == nil {
+		res, err = b.execStatement(ctx, stmts, str, args)
+	}
+	if err != nil && b.continueOnError && ctx.Err() == nil {
+		res, err = b.bisect(ctx, stmts, bt, head, endStr, err)
+	}
+
+	b.logBatch(ctx, bt, str, time.Since(start), err)
+	if err == nil {
+		b.stats.add(BatchStats{Index: bt.index, Rows: bt.rows(), Bytes: len(str), Duration: time.Since(start)})
+		if b.controller != nil {
+			b.controller.done(bt.rows())
+		}
+	}
+	if err != nil {
+		err = &BatchError{Index: bt.index, FirstRow: bt.start, Rows: bt.rows(), Err: err}
+	}
+	span.SetAttributes(slog.Int("bulk.bytes", len(str)))
+	span.End(err)
+	b.observeBatch(bt.rows(), time.Since(start), err)
+	if b.onBatchDone != nil {
+		info.Duration = time.Since(start)
+		info.Err = err
+		b.onBatchDone(info)
+	}
+	return res, err
+}
+
+// execStatement executes the statement str with vals, retrying it as the retry policy says.
+func (b *Bulk) execStatement(ctx context.Context, stmts *stmtCache, str string, vals []interface{}) (sql.Result, error) {
+	var res sql.Result
+	err := b.retry(ctx, func() error {
+		if b.interpolate {
+			// The values are already in the statement, which isn't worth preparing
+			var err error
+			res, err = stmts.db.ExecContext(ctx, str)
+			return err
+		}
+		// Prepare the statement
+		stmt, err := stmts.prepare(ctx, str)
 		if err != nil {
 			return err
 		}
-	} else { // If we have more than PLACEHOLDER_LIMIT values to insert, we have to insert the values separatly
-		// In each iteration, we will insert at least PLACEHOLDER_LIMIT values
-
-		// "batchs" is the number of times we have to divide the data
-		batchsF := float64(len(b.vals)) / float64(PLACEHOLDER_LIMIT)
-		batchs := helper.RoundUp(batchsF)
-
-		rowsPerBatch := PLACEHOLDER_LIMIT / b.valuesPerRow
-		charactersPerPlaceholder := 2 * (b.valuesPerRow + 1)
-		charactersPerBatch := rowsPerBatch * charactersPerPlaceholder
-		for i := 0; i < batchs; i++ {
-			var str string
-			var vals []interface{}
-			if i == batchs-1 {
-				str = b.initStr + b.placeholderStr[charactersPerBatch*i:]
-				vals = b.vals[rowsPerBatch*b.valuesPerRow*i:]
-			} else {
-				str = b.initStr + b.placeholderStr[charactersPerBatch*i:charactersPerBatch*(i+1)]
-				vals = b.vals[rowsPerBatch*b.valuesPerRow*i : rowsPerBatch*b.valuesPerRow*(i+1)]
-			}
+		// Format all vals at once
+		res, err = stmt.ExecContext(ctx, vals...)
+		return err
+	})
+	return res, err
+}
 
-			// Same process for len(b.vals) < PLACEHOLDER_LIMIT
-			// Trim the last ,
-			str = str[0 : len(str)-1]
-			if replaceOnDuplicate {
-				firstIndex := strings.Index(b.initStr, "(")
-				secondIndex := strings.Index(b.initStr, ")")
-				columnsStr := b.initStr[firstIndex+1 : secondIndex]
-				columns := strings.Split(columnsStr, ", ")
-				endStr := " ON DUPLICATE KEY UPDATE "
-				for _, v := range columns {
-					endStr += v + "=VALUES(" + v + "),"
-				}
-				endStr = endStr[:len(endStr)-1]
-				str += endStr
-			}
-			// Prepare the statement
-			stmt, err := db.Prepare(str)
-			if err != nil {
+// statement returns the insert statement for the rows in bt, starting with head and ending with clause.
+func (b *Bulk) statement(head string, bt batch, clause string) string {
+	source := b.rowSource()
+	if source != "" {
+		head = strings.TrimSuffix(head, "VALUES ")
+	}
+	var sb strings.Builder
+	sb.Grow(len(head) + b.placeholdersLen(bt) + len(clause))
+	sb.WriteString(head)
+	b.writeRows(&sb, bt, source, b.placeholderWriter(&sb))
+	sb.WriteString(clause)
+	return sb.String()
+}
+
+// insertHead returns the start of the insert statement beginning with verb, e.g. "INSERT INTO", up to its
+// VALUES list.
+func (b *Bulk) insertHead(verb string) string {
+	return verb + " " + b.quote(b.table) + "(" + strings.Join(b.quoteAll(b.columns), ", ") + ") VALUES "
+}
+
+// Reset clears the buffered values, so the Bulk can be reused for a new load with the same table, columns
+// and options without calling Init again. The resume row set with SetResumeRow is cleared too.
+func (b *Bulk) Reset() {
+	b.clearValues()
+	b.resumeRow = 0
+	b.received, b.rejected, b.deadLettered = 0, nil, 0
+}
+
+// clearValues removes the buffered values, keeping the capacity of the buffer. The old values are set
+// to nil so they can be garbage collected.
+func (b *Bulk) clearValues() {
+	for i := range b.vals {
+		b.vals[i] = nil
+	}
+	b.vals = b.vals[:0]
+	b.rows = 0
+	b.rawValues, b.sparse, b.blobs = false, false, false
+}
+
+// Reserve preallocates room for rows more rows, so appending them with PrepareValues doesn't grow the
+// values buffer again and again during the ingestion of a large dataset. It must be called after Init.
+func (b *Bulk) Reserve(rows int) {
+	need := len(b.vals) + rows*b.valuesPerRow
+	if rows <= 0 || need <= cap(b.vals) {
+		return
+	}
+	vals := make([]interface{}, len(b.vals), need)
+	copy(vals, b.vals)
+	b.vals = vals
+}
+
+// PrepareValues receives the values that are going to be appended to the vals members.
+// The number of values must match the columns given to Init, otherwise, it exits with an error code.
+// If SetAutoFlush was called, the buffered rows are inserted once the threshold is reached.
+func (b *Bulk) PrepareValues(vals ...interface{}) error {
+	defer b.lockInterval()()
+	if err := b.intervalErr(); err != nil {
+		return err
+	}
+	if b.table == "" && b.initErr == nil {
+		return ErrNotInitialized
+	}
+	if err := b.appendRow(vals); err != nil {
+		return err
+	}
+	if b.autoFlush != nil && b.rows >= b.flushThreshold() {
+		return b.flush(b.autoFlush.ctx)
+	}
+	return nil
+}
+
+// AddRows appends rows like PrepareValues called with each of them, but checks the number of values of
+// every row before appending any and grows the buffer once. If a row is invalid, none of rows is
+// appended, except the ones already inserted by an automatic flush (see SetAutoFlush).
+func (b *Bulk) AddRows(rows [][]interface{}) error {
+	defer b.lockInterval()()
+	if err := b.intervalErr(); err != nil {
+		return err
+	}
+	if b.table == "" && b.initErr == nil {
+		return ErrNotInitialized
+	}
+	if len(b.rowHooks) == 0 {
+		// The hooks may change the number of values
+		for _, vals := range rows {
+			if err := b.checkCount(len(vals)); err != nil {
 				return err
 			}
-			// Format all vals at once
-			_, err = stmt.Exec(vals...)
-			if err != nil {
+		}
+	}
+	b.Reserve(len(rows))
+	start, buffered, received, rejected := len(b.vals), b.rows, b.received, len(b.rejected)
+	for _, vals := range rows {
+		if err := b.appendRow(vals); err != nil {
+			for i := start; i < len(b.vals); i++ {
+				b.vals[i] = nil
+			}
+			b.vals, b.rows, b.received, b.rejected = b.vals[:start], buffered, received, b.rejected[:rejected]
+			return err
+		}
+		if b.autoFlush != nil && b.rows >= b.flushThreshold() {
+			if err := b.flush(b.autoFlush.ctx); err != nil {
 				return err
 			}
+			start, buffered, received, rejected = len(b.vals), b.rows, b.received, len(b.rejected)
 		}
 	}
 	return nil
 }
 
-// PrepareValues receives the values that are going to be appended to the vals members.
-// The number of values must match the valuesPerRow, otherwise, it exits with an error code.
-func (b *Bulk) PrepareValues(vals ...interface{}) error {
-	if len(vals) != b.valuesPerRow {
-		return fmt.Errorf("ERROR: Inserted a wrong amount of values: Inserted: %v  Required: %v \n", len(vals), b.valuesPerRow)
+// appendedColumns returns the columns Init appends after the given ones: the constant columns followed by
+// the audit columns.
+func (b *Bulk) appendedColumns() []string {
+	var columns []string
+	for _, c := range b.constants {
+		columns = append(columns, c.name)
+	}
+	return append(columns, auditColumns(b.audit)...)
+}
+
+// validateAppended checks that the appended columns are valid and don't repeat a column.
+func validateAppended(columns, appended []string) error {
+	if err := validateIdentifiers(appended); err != nil {
+		return err
 	}
-	b.placeholderStr += b.placeholderStrHelper
+	for i, c := range appended {
+		if contains(columns, c) || contains(appended[:i], c) {
+			return fmt.Errorf("ERROR: The column %v is given to Init and appended by the Bulk", c)
+		}
+	}
+	return nil
+}
+
+// checkCount returns an error if a row of n values doesn't match the columns given to Init.
+func (b *Bulk) checkCount(n int) error {
+	if required := b.valuesPerRow - b.appended; n != required {
+		return fmt.Errorf("%w: Inserted: %v  Required: %v", ErrValueCountMismatch, n, required)
+	}
+	return nil
+}
+
+// appendRow appends a row with vals, as returned by the BeforeRow hooks, and the values of the appended
+// columns, normalizing and encoding them. The row isn't appended if it isn't valid or a value can't be
+// encoded.
+func (b *Bulk) appendRow(vals []interface{}) error {
+	row := b.received
+	b.received++
+	if row < b.checkpointSkip {
+		// The row was committed by a previous run of the load
+		return nil
+	}
+	if len(b.rowHooks) > 0 {
+		var keep bool
+		if vals, keep = b.runRowHooks(row, vals); !keep {
+			return nil
+		}
+	}
+	if err := b.checkCount(len(vals)); err != nil {
+		return err
+	}
+	if err := b.validate(vals); err != nil {
+		if !b.lenient {
+			return &RowError{Row: row, Values: vals, Err: err}
+		}
+		b.rejected = append(b.rejected, &RowError{Row: row, Values: vals, Err: err})
+		return nil
+	}
+	start := len(b.vals)
 	b.vals = append(b.vals, vals...)
+	for _, c := range b.constants {
+		b.vals = append(b.vals, c.value)
+	}
+	b.vals = b.appendAudit(b.vals)
+	if err := b.normalizeDecimals(b.vals[start:]); err != nil {
+		b.vals = b.vals[:start]
+		return err
+	}
+	b.normalizeNulls(b.vals[start:])
+	// The guards fix the strings they checked, not the times formatted with a layout
+	b.fixStrings(b.vals[start:])
+	b.normalizeTimes(b.vals[start:])
+	if err := b.normalizeUUIDs(b.vals[start:]); err != nil {
+		b.vals = b.vals[:start]
+		return err
+	}
+	if err := b.checkExprs(b.vals[start:]); err != nil {
+		b.vals = b.vals[:start]
+		return err
+	}
+	if err := b.encodeRow(b.vals[start:]); err != nil {
+		b.vals = b.vals[:start]
+		return err
+	}
 	b.rows++
 	return nil
 }
@@ -0,0 +1,278 @@
+// Package bulkgorm inserts slices of GORM models with the bulk loader, as a replacement of the
+// CreateInBatches method of gorm.io/gorm, which is slow for very large datasets. The models are mapped to
+// columns as GORM does, with the naming strategy of the gorm.DB, but the statements are generated and
+// executed by bulk: the GORM hooks and callbacks aren't run. It doesn't import GORM, the handles are
+// taken from the gorm.DB by the caller:
+//
+//	sqlDB, _ := db.DB()
+//	_, err := bulkgorm.CreateInBatches(ctx, sqlDB, users, &bulkgorm.Options{Namer: db.NamingStrategy})
+package bulkgorm
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/daniloor/bulk"
+)
+
+// Namer names the tables and columns of the models, such as the schema.Namer of GORM.
+type Namer interface {
+	TableName(table string) string
+	ColumnName(table, column string) string
+}
+
+// Tabler is implemented by the models which name their table, as in GORM.
+type Tabler interface {
+	TableName() string
+}
+
+// Options configures CreateInBatches.
+type Options struct {
+	Namer     Namer        // Naming strategy of the tables and columns, usually the NamingStrategy of the gorm.DB, required
+	Dialect   bulk.Dialect // Dialect of the database, bulk.MySQL by default, see Dialect
+	Table     string       // Name of the table, overriding the one of the models
+	BatchSize int          // Maximum number of rows per statement, 0 for the limit of the dialect
+	Upsert    bool         // Update the rows which collide with an existing key, like clause.OnConflict{UpdateAll: true}
+	Conflict  []string     // Columns of the key targeted by the upsert, like clause.OnConflict.Columns, the primary key by default
+}
+
+// Dialect returns the dialect matching the Name of a GORM dialector, e.g. "postgres", or nil if there's none.
+func Dialect(name string) bulk.Dialect {
+	switch name {
+	case "mysql":
+		return bulk.MySQL
+	case "postgres":
+		return bulk.Postgres
+	case "sqlserver":
+		return bulk.SQLServer
+	case "sqlite":
+		return bulk.SQLite
+	case "clickhouse":
+		return bulk.ClickHouse
+	case "oracle":
+		return bulk.Oracle
+	}
+	return nil
+}
+
+// field is a column of a model.
+type field struct {
+	column    string
+	index     []int
+	primary   bool // The field is the primary key, filled by the database if zero
+	timestamp bool // The field is CreatedAt or UpdatedAt, set to the current time if zero
+}
+
+// CreateInBatches inserts models, a slice of structs or of pointers to structs, into db. The fields are
+// mapped as GORM does: the column tag and the naming strategy name the columns, the fields tagged with
+// gorm:"-", gorm:"->" or gorm:"<-:false" and the associations are skipped, and the embedded structs,
+// including gorm.Model, are promoted. The zero CreatedAt and UpdatedAt fields are set to the current
+// time. If every model has a zero primary key, it's left to the database and, for a plain insert of
+// pointers, the generated ids are set back into the models, as bulk.InsertReturning gets them.
+func CreateInBatches(ctx context.Context, db bulk.Execer, models interface{}, opts *Options) (*bulk.Result, error) {
+	if opts == nil || opts.Namer == nil {
+		return nil, fmt.Errorf("ERROR: CreateInBatches requires a Namer")
+	}
+	v := reflect.ValueOf(models)
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		return nil, fmt.Errorf("ERROR: CreateInBatches requires a slice of structs, got %T", models)
+	}
+	elemType := v.Type().Elem()
+	isPtr := elemType.Kind() == reflect.Ptr
+	if isPtr {
+		elemType = elemType.Elem()
+	}
+	if elemType.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("ERROR: CreateInBatches requires a slice of structs, got %T", models)
+	}
+	if v.Len() == 0 {
+		return &bulk.Result{}, nil
+	}
+	elems := make([]reflect.Value, v.Len())
+	for i := range elems {
+		if elems[i] = v.Index(i); isPtr {
+			if elems[i].IsNil() {
+				return nil, fmt.Errorf("ERROR: CreateInBatches found a nil element at index %v", i)
+			}
+			elems[i] = elems[i].Elem()
+		}
+	}
+
+	table := opts.Table
+	if table == "" {
+		if t, ok := reflect.New(elemType).Interface().(Tabler); ok {
+			table = t.TableName()
+		} else {
+			table = opts.Namer.TableName(elemType.Name())
+		}
+	}
+	fields := modelFields(elemType, opts.Namer, table, "", nil)
+	var generated *field
+	for i := 0; i < len(fields); i++ {
+		if fields[i].primary && allZero(elems, fields[i].index) {
+			f := fields[i]
+			generated = &f
+			fields = append(fields[:i], fields[i+1:]...)
+			break
+		}
+	}
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("ERROR: The model %v has no columns", elemType)
+	}
+
+	d := opts.Dialect
+	if d == nil {
+		d = bulk.MySQL
+	}
+	b := bulk.New(d)
+	columns := make([]string, len(fields))
+	for i, f := range fields {
+		columns[i] = f.column
+	}
+	b.Init(table, columns...)
+	if opts.Upsert {
+		// Postgres, SQLite, SQL Server and Oracle need the key of the conflicts
+		target := opts.Conflict
+		if len(target) == 0 {
+			for _, f := range fields {
+				if f.primary {
+					target = append(target, f.column)
+				}
+			}
+		}
+		b.SetConflictTarget(target...)
+	}
+	if opts.BatchSize > 0 {
+		b.SetBatchLimit(opts.BatchSize * len(columns))
+	}
+	now := time.Now()
+	vals := make([]interface{}, len(fields))
+	for _, elem := range elems {
+		for i, f := range fields {
+			fv := elem.FieldByIndex(f.index)
+			vals[i] = fv.Interface()
+			if f.timestamp && fv.IsZero() {
+				vals[i] = now
+				if fv.CanSet() {
+					fv.Set(reflect.ValueOf(now))
+				}
+			}
+		}
+		if err := b.PrepareValues(vals...); err != nil {
+			return nil, err
+		}
+	}
+
+	if generated == nil || opts.Upsert || !isPtr || !isInt(elemType.FieldByIndex(generated.index).Type) {
+		return b.Exec(ctx, db, opts.Upsert)
+	}
+	ids, err := b.InsertReturning(ctx, db, generated.column)
+	for i, id := range ids {
+		if i == len(elems) {
+			break
+		}
+		fv := elems[i].FieldByIndex(generated.index)
+		if fv.Kind() >= reflect.Uint && fv.Kind() <= reflect.Uintptr {
+			fv.SetUint(uint64(id))
+		} else {
+			fv.SetInt(id)
+		}
+	}
+	return &bulk.Result{RowsAffected: int64(len(ids)), Committed: len(ids)}, err
+}
+
+var (
+	timeType    = reflect.TypeOf(time.Time{})
+	valuerType  = reflect.TypeOf((*driver.Valuer)(nil)).Elem()
+	scannerType = reflect.TypeOf((*sql.Scanner)(nil)).Elem()
+)
+
+// modelFields returns the columns of the struct t of the table, whose names start with prefix. The
+// struct is the field of index parent of the model.
+func modelFields(t reflect.Type, namer Namer, table, prefix string, parent []int) []field {
+	var fields []field
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		index := append(append([]int{}, parent...), i)
+		tags := gormTags(f.Tag.Get("gorm"))
+		if _, ok := tags["-"]; ok {
+			continue
+		}
+		if read, ok := tags["->"]; ok && read != "false" && tags["<-"] == "" || tags["<-"] == "false" {
+			// The read-only fields aren't inserted
+			continue
+		}
+		if _, embedded := tags["EMBEDDED"]; (f.Anonymous || embedded) && f.Type.Kind() == reflect.Struct && !isColumn(f.Type) {
+			fields = append(fields, modelFields(f.Type, namer, table, prefix+tags["EMBEDDEDPREFIX"], index)...)
+			continue
+		}
+		if f.PkgPath != "" || !isColumn(f.Type) {
+			// The unexported fields and the associations aren't columns
+			continue
+		}
+		column := tags["COLUMN"]
+		if column == "" {
+			column = namer.ColumnName(table, f.Name)
+		}
+		_, primary := tags["PRIMARYKEY"]
+		fields = append(fields, field{
+			column:    prefix + column,
+			index:     index,
+			primary:   primary || f.Name == "ID",
+			timestamp: (f.Name == "CreatedAt" || f.Name == "UpdatedAt") && f.Type == timeType,
+		})
+	}
+	return fields
+}
+
+// isColumn reports whether the fields of type t hold a column, rather than an association.
+func isColumn(t reflect.Type) bool {
+	if t.Implements(valuerType) || reflect.PtrTo(t).Implements(scannerType) {
+		return true
+	}
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.Struct:
+		return t == timeType
+	case reflect.Slice:
+		return t.Elem().Kind() == reflect.Uint8
+	case reflect.Map, reflect.Array, reflect.Chan, reflect.Func, reflect.Interface:
+		return false
+	}
+	return true
+}
+
+// gormTags parses the settings of a gorm tag, e.g. "column:name;primaryKey", with their keys uppercased.
+func gormTags(tag string) map[string]string {
+	tags := map[string]string{}
+	for _, s := range strings.Split(tag, ";") {
+		if s = strings.TrimSpace(s); s == "" {
+			continue
+		}
+		key, value, _ := strings.Cut(s, ":")
+		tags[strings.ToUpper(strings.TrimSpace(key))] = strings.TrimSpace(value)
+	}
+	return tags
+}
+
+// allZero reports whether the field of index is zero in every element.
+func allZero(elems []reflect.Value, index []int) bool {
+	for _, elem := range elems {
+		if !elem.FieldByIndex(index).IsZero() {
+			return false
+		}
+	}
+	return true
+}
+
+// isInt reports whether t is an integer type.
+func isInt(t reflect.Type) bool {
+	return t.Kind() >= reflect.Int && t.Kind() <= reflect.Uint64
+}
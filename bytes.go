@@ -0,0 +1,82 @@
+package bulk
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"time"
+)
+
+// SetMaxBytes limits the estimated size of each batch, its SQL and its values, to n bytes, e.g. to stay
+// below the max_allowed_packet of MySQL when the rows hold long strings or blobs, which PLACEHOLDER_LIMIT
+// doesn't account for. The strings and bytes count their length and the other values a fixed size, so n
+// should leave some margin. A row larger than n is inserted in a batch on its own. A value of 0 disables
+// the limit.
+func (b *Bulk) SetMaxBytes(n int) {
+	b.maxBytes = n
+}
+
+// MaxAllowedPacket returns the max_allowed_packet of the MySQL server of db, to be given to SetMaxBytes.
+func MaxAllowedPacket(ctx context.Context, db Querier) (int, error) {
+	rows, err := db.QueryContext(ctx, "SELECT @@max_allowed_packet")
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return 0, err
+		}
+		return 0, fmt.Errorf("ERROR: The server didn't return max_allowed_packet")
+	}
+	var n int
+	if err := rows.Scan(&n); err != nil {
+		return 0, err
+	}
+	return n, rows.Err()
+}
+
+// sizedBatches splits the rows into batches of at most limit values whose estimated size, adding the
+// overhead bytes of the statement, doesn't exceed the maximum set with SetMaxBytes.
+func (b *Bulk) sizedBatches(limit, overhead int) []batch {
+	rowsPerBatch := limit / b.valuesPerRow
+	// Every value is written as a placeholder and a separator
+	valueOverhead := len(b.dialect.Placeholder(limit)) + 1
+	var batches []batch
+	start, size := b.resumeRow, overhead
+	for r := b.resumeRow; r < b.rows; r++ {
+		rowSize := 2
+		for _, v := range b.vals[r*b.valuesPerRow : (r+1)*b.valuesPerRow] {
+			rowSize += valueSize(v) + valueOverhead
+		}
+		if r > start && (r-start == rowsPerBatch || size+rowSize > b.maxBytes) {
+			batches = append(batches, batch{index: len(batches), start: start, end: r})
+			start, size = r, overhead
+		}
+		size += rowSize
+	}
+	if start < b.rows {
+		batches = append(batches, batch{index: len(batches), start: start, end: b.rows})
+	}
+	return batches
+}
+
+// valueSize estimates the bytes sent for v.
+func valueSize(v interface{}) int {
+	switch v := v.(type) {
+	case nil, bool:
+		return 1
+	case string:
+		return len(v)
+	case []byte:
+		return len(v)
+	case Raw:
+		return len(v)
+	case time.Time:
+		return 12
+	}
+	if rv := reflect.ValueOf(v); rv.Kind() == reflect.String || rv.Kind() == reflect.Slice && rv.Type().Elem().Kind() == reflect.Uint8 {
+		return rv.Len()
+	}
+	return 8
+}
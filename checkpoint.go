@@ -0,0 +1,115 @@
+package bulk
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// CheckpointStore records how many rows of each load were committed, so a load which crashed can be
+// resumed. FileCheckpointStore stores them in files, other stores can be a table of the database.
+type CheckpointStore interface {
+	// Load returns the rows committed by the load id, 0 if it has no checkpoint.
+	Load(ctx context.Context, id string) (int, error)
+	// Save records that the first rows rows received by the load id are committed.
+	Save(ctx context.Context, id string, rows int) error
+}
+
+// SetCheckpoint makes the Bulk record in store, under the load id, the number of rows received by
+// PrepareValues, and the methods built on it, which are committed, and skip as many rows as the store holds
+// for id, which were committed by a previous run of the load. The checkpoint is saved after every Exec or
+// flush which inserts all the buffered rows, so the streaming mode (see SetAutoFlush) saves it regularly.
+// If a run fails in the middle of a flush, the next one inserts again the rows of the flush which were
+// committed, unless they are inserted with SetAtomic or as upserts. The rows must be received in the same
+// order by every run of the load. It must be called after Init.
+func (b *Bulk) SetCheckpoint(ctx context.Context, store CheckpointStore, id string) error {
+	rows, err := store.Load(ctx, id)
+	if err != nil {
+		return err
+	}
+	b.checkpoint = &checkpoint{store: store, id: id}
+	b.checkpointSkip = rows
+	return nil
+}
+
+// checkpoint is the store and the id set with SetCheckpoint.
+type checkpoint struct {
+	store CheckpointStore
+	id    string
+}
+
+// saveCheckpoint records that every row received so far is committed.
+func (b *Bulk) saveCheckpoint(ctx context.Context) error {
+	if b.checkpoint == nil {
+		return nil
+	}
+	rows := b.received
+	if rows < b.checkpointSkip {
+		rows = b.checkpointSkip
+	}
+	return b.checkpoint.store.Save(ctx, b.checkpoint.id, rows)
+}
+
+// FileCheckpointStore is a CheckpointStore keeping the checkpoint of each load in a file of a directory.
+type FileCheckpointStore struct {
+	dir string
+}
+
+// NewFileCheckpointStore returns a FileCheckpointStore keeping the checkpoints in dir, which must exist.
+func NewFileCheckpointStore(dir string) *FileCheckpointStore {
+	return &FileCheckpointStore{dir: dir}
+}
+
+// path returns the path of the file of the load id.
+func (s *FileCheckpointStore) path(id string) string {
+	return filepath.Join(s.dir, url.PathEscape(id)+".checkpoint")
+}
+
+// Load returns the rows committed by the load id, 0 if it has no checkpoint file.
+func (s *FileCheckpointStore) Load(ctx context.Context, id string) (int, error) {
+	data, err := os.ReadFile(s.path(id))
+	if errors.Is(err, os.ErrNotExist) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return strconv.Atoi(strings.TrimSpace(string(data)))
+}
+
+// Save writes the checkpoint of the load id. The file is replaced atomically, so a crash while saving
+// leaves the previous checkpoint.
+func (s *FileCheckpointStore) Save(ctx context.Context, id string, rows int) error {
+	f, err := os.CreateTemp(s.dir, ".checkpoint-*")
+	if err != nil {
+		return err
+	}
+	_, err = f.WriteString(strconv.Itoa(rows) + "\n")
+	if err == nil {
+		err = f.Sync()
+	}
+	if cErr := f.Close(); err == nil {
+		err = cErr
+	}
+	if err == nil {
+		err = os.Rename(f.Name(), s.path(id))
+	}
+	if err != nil {
+		os.Remove(f.Name())
+	}
+	return err
+}
+
+// Delete removes the checkpoint of the load id, once it's completed, so a new load with the same id
+// starts from the first row.
+func (s *FileCheckpointStore) Delete(id string) error {
+	err := os.Remove(s.path(id))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,99 @@
+package bulk
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCheckpoint(t *testing.T) {
+	tests := []struct {
+		name     string
+		saved    int // Rows committed by a previous run
+		rows     int // Rows received by this run
+		failAt   int // Row whose statement fails, -1 for none
+		wantArgs string
+		wantSave int
+	}{
+		{name: "first run", saved: 0, rows: 4, failAt: -1, wantArgs: "[[0 1] [2 3]]", wantSave: 4},
+		{name: "resumed run", saved: 3, rows: 5, failAt: -1, wantArgs: "[[3 4]]", wantSave: 5},
+		{name: "completed run", saved: 5, rows: 5, failAt: -1, wantArgs: "[]", wantSave: 5},
+		{name: "failed run", saved: 0, rows: 4, failAt: 2, wantArgs: "[[0 1] [2 3]]", wantSave: 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			store := NewFileCheckpointStore(t.TempDir())
+			if tt.saved > 0 {
+				if err := store.Save(ctx, "load", tt.saved); err != nil {
+					t.Fatal(err)
+				}
+			}
+			r := NewRecorder()
+			r.SetResponder(func(s Statement) (int64, error) {
+				if s.Args[0] == tt.failAt {
+					return 0, fmt.Errorf("boom")
+				}
+				return int64(len(s.Args)), nil
+			})
+			b := New(Postgres)
+			b.Init("t", "id")
+			if err := b.SetCheckpoint(ctx, store, "load"); err != nil {
+				t.Fatal(err)
+			}
+			b.SetBatchLimit(2)
+			b.SetAutoFlush(ctx, r.DB(), 2, false)
+			var err error
+			for i := 0; i < tt.rows && err == nil; i++ {
+				err = b.PrepareValues(i)
+			}
+			if err == nil {
+				err = b.Close()
+			}
+			if (err != nil) != (tt.failAt >= 0) {
+				t.Fatalf("got the error %v", err)
+			}
+			if got := fmt.Sprint(recordedArgs(r)); got != tt.wantArgs {
+				t.Errorf("got the statements %v, expected %v", got, tt.wantArgs)
+			}
+			saved, err := store.Load(ctx, "load")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if saved != tt.wantSave {
+				t.Errorf("got the checkpoint %v, expected %v", saved, tt.wantSave)
+			}
+		})
+	}
+}
+
+func TestFileCheckpointStore(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	s := NewFileCheckpointStore(dir)
+	if rows, err := s.Load(ctx, "a/b"); err != nil || rows != 0 {
+		t.Fatalf("got %v, %v without checkpoint, expected 0", rows, err)
+	}
+	if err := s.Save(ctx, "a/b", 42); err != nil {
+		t.Fatal(err)
+	}
+	if rows, err := s.Load(ctx, "a/b"); err != nil || rows != 42 {
+		t.Fatalf("got %v, %v, expected 42", rows, err)
+	}
+	// The id is escaped and no temporary file is left
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 1 || entries[0].Name() != "a%2Fb.checkpoint" {
+		t.Errorf("got the files %v, expected a%%2Fb.checkpoint", entries)
+	}
+	if err := s.Delete("a/b"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "a%2Fb.checkpoint")); !os.IsNotExist(err) {
+		t.Errorf("the checkpoint wasn't deleted: %v", err)
+	}
+	if err := s.Delete("a/b"); err != nil {
+		t.Errorf("deleting a missing checkpoint failed: %v", err)
+	}
+}
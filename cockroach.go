@@ -0,0 +1,143 @@
+package bulk
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// CockroachDB uses $1, $2, ... placeholders, "double quote" quoting, ON CONFLICT ... DO UPDATE and, when
+// no conflict target is set and every inserted column is overwritten, UPSERT. Its batches have at most
+// 500 rows, as smaller transactions are less likely to conflict, and the transactions started by the Bulk
+// are retried as TxRestarter describes. The batches which don't run inside a transaction can be retried
+// with SetRetryPolicy.
+var CockroachDB Dialect = cockroachDialect{}
+
+type cockroachDialect struct{}
+
+func (cockroachDialect) Placeholder(n int) string {
+	return postgresDialect{}.Placeholder(n)
+}
+
+func (cockroachDialect) QuoteIdentifier(name string) string {
+	return postgresDialect{}.QuoteIdentifier(name)
+}
+
+func (cockroachDialect) Upsert(c *Conflict) (string, string, error) {
+	if c.Action == DoUpdate && len(c.Target) == 0 {
+		// UPSERT overwrites every inserted column of the rows with the same primary key
+		if len(c.Set) > 0 || c.UpdateWhere != "" || !updatesAll(c) {
+			return "", "", fmt.Errorf("ERROR: CockroachDB upserts which don't overwrite every inserted column require a conflict target, use SetConflictTarget")
+		}
+		return "UPSERT INTO", "", nil
+	}
+	return postgresDialect{}.Upsert(c)
+}
+
+// updatesAll reports whether the upsert c overwrites every inserted column.
+func updatesAll(c *Conflict) bool {
+	for _, v := range c.Columns {
+		if !contains(c.Update, v) {
+			return false
+		}
+	}
+	return true
+}
+
+func (cockroachDialect) MaxParameters() int {
+	return 65535
+}
+
+func (cockroachDialect) MaxRows() int {
+	return 500
+}
+
+func (cockroachDialect) Returning(column string) string {
+	return " RETURNING " + column
+}
+
+func (cockroachDialect) CurrentSchema() string {
+	return "current_schema()"
+}
+
+func (cockroachDialect) ColumnType(kind Kind) string {
+	return postgresDialect{}.ColumnType(kind)
+}
+
+func (cockroachDialect) CreateTable(table string, definitions []string) string {
+	return createTable(table, definitions, "")
+}
+
+func (cockroachDialect) Literal(v driver.Value) (string, error) {
+	return postgresLiterals.literal(v)
+}
+
+func (cockroachDialect) RestartSavepoint() string {
+	return "cockroach_restart"
+}
+
+// TxRestarter is implemented by the dialects whose transactions must be retried by the client when they fail
+// with a serialization error (40001), such as CockroachDB. The transactions started by the Bulk set a
+// savepoint first and, on such an error, roll back to it and run again, up to maxRestarts times, releasing
+// it before committing. The transactions of the caller, given as a *sql.Tx, aren't retried.
+type TxRestarter interface {
+	// RestartSavepoint returns the name of the savepoint, e.g. "cockroach_restart".
+	RestartSavepoint() string
+}
+
+// maxRestarts is the number of times a transaction of a TxRestarter dialect is run again.
+const maxRestarts = 10
+
+// withTx calls fn inside a transaction started on db like the withTx function, retrying it as TxRestarter
+// describes if the dialect implements it.
+func (b *Bulk) withTx(ctx context.Context, db Execer, fn func(tx Execer) error) error {
+	r, ok := b.dialect.(TxRestarter)
+	if !ok {
+		return withTx(ctx, db, fn)
+	}
+	if tx, ok := db.(*sql.Tx); ok {
+		return fn(tx)
+	}
+	beginner, ok := db.(TxBeginner)
+	if !ok {
+		return fmt.Errorf("ERROR: %T can't start a transaction", db)
+	}
+	tx, err := beginner.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	savepoint := r.RestartSavepoint()
+	if _, err := tx.ExecContext(ctx, "SAVEPOINT "+savepoint); err != nil {
+		tx.Rollback()
+		return err
+	}
+	for restarts := 0; ; restarts++ {
+		err := fn(tx)
+		if err == nil {
+			_, err = tx.ExecContext(ctx, "RELEASE SAVEPOINT "+savepoint)
+		}
+		if err == nil {
+			return tx.Commit()
+		}
+		if restarts >= maxRestarts || !isRestartError(err) || ctx.Err() != nil {
+			tx.Rollback()
+			return err
+		}
+		if _, rErr := tx.ExecContext(ctx, "ROLLBACK TO SAVEPOINT "+savepoint); rErr != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+}
+
+// isRestartError reports whether err is a serialization failure which requires restarting the transaction.
+func isRestartError(err error) bool {
+	var s sqlStater
+	if errors.As(err, &s) {
+		return s.SQLState() == "40001"
+	}
+	return strings.Contains(err.Error(), "restart transaction")
+}
@@ -0,0 +1,69 @@
+package bulk
+
+import (
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// SetComment makes the insert statements carry a comment with tags in the sqlcommenter format, e.g.
+// /*app='billing',job_id='42'*/, so the slow bulk queries found in pg_stat_statements or Performance
+// Insights can be attributed to the job which ran them. The comment is appended to the statements, as
+// sqlcommenter does, or prepended to them if prepend is true, as some tools only keep the start of the
+// statements. The tags are URL encoded, so they can't end the comment. Empty tags disable the comment.
+func (b *Bulk) SetComment(tags map[string]string, prepend bool) {
+	b.comment = sqlComment(tags)
+	b.commentFirst = prepend
+}
+
+// withComment returns head and clause with the comment set with SetComment.
+func (b *Bulk) withComment(head, clause string) (string, string) {
+	if b.comment == "" {
+		return head, clause
+	}
+	if b.commentFirst {
+		return b.comment + " " + head, clause
+	}
+	return head, clause + " " + b.comment
+}
+
+// SetHints makes the insert statements carry the optimizer hints comment /*+ hints */ after their first
+// keyword, e.g. INSERT /*+ SET_VAR(...) */ INTO, as MySQL, TiDB and Oracle expect. The hints are written
+// as is, separated by spaces. No hints disable the comment.
+func (b *Bulk) SetHints(hints ...string) {
+	b.hints = ""
+	if len(hints) > 0 {
+		b.hints = "/*+ " + strings.Join(hints, " ") + " */"
+	}
+}
+
+// withHints returns head with the hints comment set with SetHints after its first keyword.
+func (b *Bulk) withHints(head string) string {
+	i := strings.IndexByte(head, ' ')
+	if b.hints == "" || i < 0 {
+		return head
+	}
+	return head[:i] + " " + b.hints + head[i:]
+}
+
+// sqlComment returns the sqlcommenter comment holding tags, sorted by key, or "" if there are no tags.
+func sqlComment(tags map[string]string) string {
+	if len(tags) == 0 {
+		return ""
+	}
+	keys := make([]string, 0, len(tags))
+	for k := range tags {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	pairs := make([]string, len(keys))
+	for i, k := range keys {
+		pairs[i] = commentEscape(k) + "='" + commentEscape(tags[k]) + "'"
+	}
+	return "/*" + strings.Join(pairs, ",") + "*/"
+}
+
+// commentEscape URL encodes s as sqlcommenter does, with the spaces as %20.
+func commentEscape(s string) string {
+	return strings.ReplaceAll(url.QueryEscape(s), "+", "%20")
+}
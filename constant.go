@@ -0,0 +1,28 @@
+package bulk
+
+import "fmt"
+
+// constantColumn is a column set to the same value in every row.
+type constantColumn struct {
+	name  string
+	value interface{}
+}
+
+// WithConstantColumn makes Init append column after the given columns, set to v in every row, so the
+// values of PrepareValues and the methods built on it don't include it, e.g. the tenant of a multi-tenant
+// table. It must be called before Init to add the column; once added, calling it again changes the value
+// of the rows appended from then on. A new column given after Init isn't added, and makes the insert
+// fail until Init is called again, as the buffered rows have no value for it.
+func (b *Bulk) WithConstantColumn(column string, v interface{}) {
+	for i, c := range b.constants {
+		if c.name == column {
+			b.constants[i].value = v
+			return
+		}
+	}
+	if b.table != "" {
+		b.initErr = fmt.Errorf("ERROR: The constant column %v must be added before Init", column)
+		return
+	}
+	b.constants = append(b.constants, constantColumn{name: column, value: v})
+}
@@ -0,0 +1,45 @@
+package bulk
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestConstantColumn(t *testing.T) {
+	b := New(MySQL)
+	b.WithConstantColumn("tenant", 7)
+	b.Init("t", "a")
+	b.PrepareValues(1)
+	// A new value applies to the rows appended from then on
+	b.WithConstantColumn("tenant", 8)
+	b.PrepareValues(2)
+	statements, err := b.BuildStatements(false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Statement{{SQL: "INSERT INTO `t`(`a`, `tenant`) VALUES (?,?),(?,?)", Args: []interface{}{1, 7, 2, 8}}}
+	if !reflect.DeepEqual(statements, want) {
+		t.Errorf("got %v, expected %v", statements, want)
+	}
+}
+
+func TestConstantColumnAfterInit(t *testing.T) {
+	b := New(MySQL)
+	b.Init("t", "a")
+	b.WithConstantColumn("tenant", 7)
+	b.PrepareValues(1)
+	b.PrepareValues(2)
+	_, err := b.BuildStatements(false)
+	checkError(t, err, "The constant column tenant must be added before Init")
+	// The column isn't added, so Init starts a valid load without it
+	b.Init("t", "a")
+	b.PrepareValues(1)
+	statements, err := b.BuildStatements(false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Statement{{SQL: "INSERT INTO `t`(`a`) VALUES (?)", Args: []interface{}{1}}}
+	if !reflect.DeepEqual(statements, want) {
+		t.Errorf("got %v, expected %v", statements, want)
+	}
+}
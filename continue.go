@@ -0,0 +1,79 @@
+package bulk
+
+import (
+	"context"
+	"database/sql"
+)
+
+// SetContinueOnError enables the continue on error mode: when a batch fails, it's split in halves which
+// are inserted again, recursively, until the rows failing on their own are found. Those rows are
+// reported in Result.RowErrors and the rest are inserted. A batch with a single bad row costs about
+// 2*log2(rows) extra statements.
+//
+// The mode must not be used inside a Postgres transaction (SetAtomic, SetCommitEvery or a *sql.Tx),
+// since the first failure aborts the transaction and every following statement fails too.
+func (b *Bulk) SetContinueOnError(on bool) {
+	b.continueOnError = on
+}
+
+// partialResult is the result of a batch which was inserted in parts by bisect.
+type partialResult struct {
+	results   []sql.Result
+	rowErrors []*RowError
+}
+
+func (r *partialResult) LastInsertId() (int64, error) {
+	if len(r.results) == 0 {
+		return 0, nil
+	}
+	return r.results[0].LastInsertId()
+}
+
+func (r *partialResult) RowsAffected() (int64, error) {
+	var total int64
+	for _, res := range r.results {
+		affected, err := res.RowsAffected()
+		if err != nil {
+			return 0, err
+		}
+		total += affected
+	}
+	return total, nil
+}
+
+// bisect inserts the rows of bt, which failed as a whole with err, splitting them in halves until the
+// rows failing alone are found. It only returns an error if ctx is done.
+func (b *Bulk) bisect(ctx context.Context, stmts *stmtCache, bt batch, head, endStr string, err error) (sql.Result, error) {
+	p := &partialResult{}
+	if err := b.bisectInto(ctx, stmts, bt, head, endStr, err, p); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// bisectInto works like bisect, adding the results and the row errors to p.
+func (b *Bulk) bisectInto(ctx context.Context, stmts *stmtCache, bt batch, head, endStr string, err error, p *partialResult) error {
+	if bt.rows() == 1 {
+		p.rowErrors = append(p.rowErrors, &RowError{Row: bt.start, Values: b.values(bt), Err: err})
+		return nil
+	}
+	mid := bt.start + bt.rows()/2
+	for _, half := range []batch{{index: bt.index, start: bt.start, end: mid}, {index: bt.index, start: mid, end: bt.end}} {
+		str, args, err := b.batchStatement(head, half, endStr)
+		var res sql.Result
+		if err == nil {
+			res, err = b.execStatement(ctx, stmts, str, args)
+		}
+		if err == nil {
+			p.results = append(p.results, res)
+			continue
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if err := b.bisectInto(ctx, stmts, half, head, endStr, err, p); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,68 @@
+package bulk
+
+import (
+	"context"
+	"fmt"
+	"testing"
+)
+
+func TestContinueOnError(t *testing.T) {
+	r := NewRecorder()
+	r.SetResponder(func(s Statement) (int64, error) {
+		for _, a := range s.Args {
+			if a == 2 || a == 5 {
+				return 0, fmt.Errorf("bad row %v", a)
+			}
+		}
+		return int64(len(s.Args)), nil
+	})
+	b := New(MySQL)
+	b.Init("t", "id")
+	b.SetContinueOnError(true)
+	for i := 0; i < 8; i++ {
+		b.PrepareValues(i)
+	}
+	result, err := b.Exec(context.Background(), r.DB(), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The failed batch is split in halves until the bad rows are alone
+	want := "[[0 1 2 3 4 5 6 7] [0 1 2 3] [0 1] [2 3] [2] [3] [4 5 6 7] [4 5] [4] [5] [6 7]]"
+	if got := fmt.Sprint(recordedArgs(r)); got != want {
+		t.Errorf("got the statements %v, expected %v", got, want)
+	}
+	if result.RowsAffected != 6 || result.Committed != 8 {
+		t.Errorf("got %+v, expected the 6 good rows", result)
+	}
+	if len(result.RowErrors) != 2 {
+		t.Fatalf("got %v row errors, expected 2", len(result.RowErrors))
+	}
+	for i, row := range []int{2, 5} {
+		e := result.RowErrors[i]
+		if e.Row != row || fmt.Sprint(e.Values) != fmt.Sprintf("[%v]", row) || e.Err.Error() != fmt.Sprintf("bad row %v", row) {
+			t.Errorf("got the row error %+v, expected the row %v", e, row)
+		}
+	}
+}
+
+func TestContinueOnErrorCanceled(t *testing.T) {
+	r := NewRecorder()
+	ctx, cancel := context.WithCancel(context.Background())
+	r.SetResponder(func(s Statement) (int64, error) {
+		cancel()
+		return 0, fmt.Errorf("bad row")
+	})
+	b := New(MySQL)
+	b.Init("t", "id")
+	b.SetContinueOnError(true)
+	b.PrepareValues(1)
+	b.PrepareValues(2)
+	// The bisection stops once ctx is done
+	_, err := b.Exec(ctx, r.DB(), false)
+	if err == nil {
+		t.Fatal("expected the error of the canceled context")
+	}
+	if n := len(r.Statements()); n > 2 {
+		t.Errorf("got %v statements, expected the bisection to stop", n)
+	}
+}
@@ -0,0 +1,93 @@
+package bulk
+
+import (
+	"context"
+	"sync"
+)
+
+// Controller pauses and resumes the loads of the Bulks it's set on, and reports their progress, e.g. so
+// an operator can relieve the pressure on the database without killing a long streaming load. The load
+// stops between batches: the batch being executed when Pause is called is completed. In the streaming
+// mode, the producers block in PrepareValues once the next flush waits. It's safe for concurrent use.
+type Controller struct {
+	mu      sync.Mutex
+	resumed chan struct{} // Closed when the load isn't paused, replaced by Pause
+	rows    int
+	batches int
+}
+
+// Progress tells the progress of the loads of a Controller.
+type Progress struct {
+	Rows    int  // Rows of the executed batches
+	Batches int  // Number of executed batches
+	Paused  bool // Whether the loads are paused
+}
+
+// NewController returns a Controller which isn't paused.
+func NewController() *Controller {
+	resumed := make(chan struct{})
+	close(resumed)
+	return &Controller{resumed: resumed}
+}
+
+// SetController makes the batches of the loads wait while c is paused, and report their progress to it.
+// A nil c removes the controller.
+func (b *Bulk) SetController(c *Controller) {
+	b.controller = c
+}
+
+// Pause makes the loads stop before their next batch. It does nothing if they are already paused.
+func (c *Controller) Pause() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	select {
+	case <-c.resumed:
+		c.resumed = make(chan struct{})
+	default:
+	}
+}
+
+// Resume lets the paused loads continue. It does nothing if they aren't paused.
+func (c *Controller) Resume() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	select {
+	case <-c.resumed:
+	default:
+		close(c.resumed)
+	}
+}
+
+// Progress returns the progress of the loads so far.
+func (c *Controller) Progress() Progress {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	p := Progress{Rows: c.rows, Batches: c.batches}
+	select {
+	case <-c.resumed:
+	default:
+		p.Paused = true
+	}
+	return p
+}
+
+// wait blocks while c is paused, or until ctx is done.
+func (c *Controller) wait(ctx context.Context) error {
+	c.mu.Lock()
+	resumed := c.resumed
+	c.mu.Unlock()
+	select {
+	case <-resumed:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// done records an executed batch of rows rows.
+func (c *Controller) done(rows int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.rows += rows
+	c.batches++
+}
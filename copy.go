@@ -0,0 +1,97 @@
+package bulk
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+// PostgresCopy is a Postgres Backend which loads the rows with COPY ... FROM STDIN, much faster than insert
+// statements for large loads. It uses the COPY support of github.com/lib/pq, which sends the rows given to
+// the executions of the prepared COPY statement and finishes the copy on the execution without arguments.
+// The copy runs inside a transaction, the one of db if it's a *sql.Tx. COPY can't handle duplicates, so
+// upserts and ignoring duplicates aren't supported.
+var PostgresCopy Backend = postgresCopy{}
+
+// postgresCopy is the Backend of PostgresCopy.
+type postgresCopy struct{}
+
+// Load copies the rows of t into the table.
+func (postgresCopy) Load(ctx context.Context, db Execer, t *Table) (sql.Result, error) {
+	if t.Conflict != nil {
+		return nil, fmt.Errorf("ERROR: COPY can't handle duplicates")
+	}
+	// The statement is the one built by pq.CopyIn
+	str := "COPY " + t.QuotedName + " (" + strings.Join(t.QuotedColumns, ", ") + ") FROM STDIN"
+	return copyRows(ctx, db, str, t.Rows, true)
+}
+
+// SQLServerCopy returns a SQL Server Backend which loads the rows with the bulk copy of
+// github.com/microsoft/go-mssqldb, lifting the limit of 2100 parameters per statement. copyIn builds
+// the bulk copy statement, usually wrapping mssql.CopyIn:
+//
+//	bulk.SQLServerCopy(func(table string, columns ...string) string {
+//		return mssql.CopyIn(table, mssql.BulkOptions{}, columns...)
+//	})
+//
+// The copy runs inside a transaction, the one of db if it's a *sql.Tx. Duplicates can't be handled.
+func SQLServerCopy(copyIn func(table string, columns ...string) string) Backend {
+	return sqlServerCopy{copyIn: copyIn}
+}
+
+// sqlServerCopy is the Backend returned by SQLServerCopy.
+type sqlServerCopy struct {
+	copyIn func(table string, columns ...string) string
+}
+
+// Load copies the rows of t into the table.
+func (c sqlServerCopy) Load(ctx context.Context, db Execer, t *Table) (sql.Result, error) {
+	if t.Conflict != nil {
+		return nil, fmt.Errorf("ERROR: The SQL Server bulk copy can't handle duplicates")
+	}
+	// The driver quotes the names itself
+	return copyRows(ctx, db, c.copyIn(t.Name, t.Columns...), t.Rows, true)
+}
+
+// ClickHouseBatch is a ClickHouse Backend which sends the rows with the batch API of
+// github.com/ClickHouse/clickhouse-go: an INSERT without VALUES is prepared inside a transaction, executed
+// once per row to append it to the batch, and the batch is sent on commit. The transaction is the one of
+// db if it's a *sql.Tx, in which case the rows are sent when the caller commits. Duplicates can't be handled.
+var ClickHouseBatch Backend = clickHouseBatch{}
+
+// clickHouseBatch is the Backend of ClickHouseBatch.
+type clickHouseBatch struct{}
+
+// Load sends the rows of t as a batch.
+func (clickHouseBatch) Load(ctx context.Context, db Execer, t *Table) (sql.Result, error) {
+	if t.Conflict != nil {
+		return nil, fmt.Errorf("ERROR: ClickHouse can't handle duplicates")
+	}
+	str := "INSERT INTO " + t.QuotedName + " (" + strings.Join(t.QuotedColumns, ", ") + ")"
+	return copyRows(ctx, db, str, t.Rows, false)
+}
+
+// copyRows executes the prepared copy statement str once per row inside a transaction, and if finish is
+// true, once without arguments to finish the copy. This is how the drivers expose their copy protocol
+// through database/sql.
+func copyRows(ctx context.Context, db Execer, str string, rows [][]interface{}, finish bool) (sql.Result, error) {
+	var res sql.Result
+	err := withTx(ctx, db, func(tx Execer) error {
+		stmt, err := tx.PrepareContext(ctx, str)
+		if err != nil {
+			return err
+		}
+		defer stmt.Close()
+		for _, row := range rows {
+			if res, err = stmt.ExecContext(ctx, row...); err != nil {
+				return err
+			}
+		}
+		if finish {
+			res, err = stmt.ExecContext(ctx)
+		}
+		return err
+	})
+	return res, err
+}
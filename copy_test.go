@@ -0,0 +1,89 @@
+package bulk
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestPostgresCopy(t *testing.T) {
+	r := NewRecorder()
+	b := New(Postgres)
+	b.Init("t", "id", "name")
+	b.SetBackend(PostgresCopy)
+	b.PrepareValues(1, "a")
+	b.PrepareValues(2, nil)
+	if err := b.Insert(r.DB(), false); err != nil {
+		t.Fatal(err)
+	}
+	// The rows are sent to the COPY statement, which the execution without arguments finishes
+	copyIn := `COPY "t" ("id", "name") FROM STDIN`
+	err := r.Expect(
+		Statement{SQL: "BEGIN"},
+		Statement{SQL: copyIn, Args: []interface{}{1, "a"}},
+		Statement{SQL: copyIn, Args: []interface{}{2, nil}},
+		Statement{SQL: copyIn},
+		Statement{SQL: "COMMIT"},
+	)
+	if err != nil {
+		t.Error(err)
+	}
+
+	b.SetConflictTarget("id")
+	checkError(t, b.Insert(r.DB(), true), "COPY can't handle duplicates")
+}
+
+func TestSQLServerCopy(t *testing.T) {
+	r := NewRecorder()
+	b := New(SQLServer)
+	b.Init("t", "id", "name")
+	// The driver gets the unquoted names
+	b.SetBackend(SQLServerCopy(func(table string, columns ...string) string {
+		return "COPY " + table + " " + strings.Join(columns, ",")
+	}))
+	for i := 0; i < 3000; i++ {
+		b.PrepareValues(i, "n")
+	}
+	if err := b.Insert(r.DB(), false); err != nil {
+		t.Fatal(err)
+	}
+	// The rows aren't split by the limit of 2100 parameters
+	statements := r.Statements()
+	if len(statements) != 3003 {
+		t.Fatalf("got %v statements, expected a transaction with 3000 rows and the finish", len(statements))
+	}
+	if s := statements[1]; s.SQL != "COPY t id,name" || fmt.Sprint(s.Args) != "[0 n]" {
+		t.Errorf("got %v", s)
+	}
+	if s := statements[3001]; s.SQL != "COPY t id,name" || len(s.Args) != 0 {
+		t.Errorf("got %v, expected the finish of the copy", s)
+	}
+
+	b.SetConflictTarget("id")
+	checkError(t, b.Insert(r.DB(), true), "The SQL Server bulk copy can't handle duplicates")
+}
+
+func TestClickHouseBatch(t *testing.T) {
+	r := NewRecorder()
+	b := New(ClickHouse)
+	b.Init("t", "id", "name")
+	b.SetBackend(ClickHouseBatch)
+	b.PrepareValues(1, "a")
+	b.PrepareValues(2, "b")
+	if err := b.Insert(r.DB(), false); err != nil {
+		t.Fatal(err)
+	}
+	// Each execution appends a row to the batch, which the commit sends
+	err := r.Expect(
+		Statement{SQL: "BEGIN"},
+		Statement{SQL: "INSERT INTO `t` (`id`, `name`)", Args: []interface{}{1, "a"}},
+		Statement{SQL: "INSERT INTO `t` (`id`, `name`)", Args: []interface{}{2, "b"}},
+		Statement{SQL: "COMMIT"},
+	)
+	if err != nil {
+		t.Error(err)
+	}
+
+	b.SetIgnoreDuplicates(true)
+	checkError(t, b.Insert(r.DB(), false), "ClickHouse can't handle duplicates")
+}
@@ -0,0 +1,186 @@
+package bulk
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// TableCreator is implemented by the dialects which can create tables, which all the provided dialects do.
+type TableCreator interface {
+	// ColumnType returns the SQL type of a column holding values of kind, e.g. "BIGINT" for KindInt.
+	ColumnType(kind Kind) string
+	// CreateTable returns the statement creating table, unless it exists, with the column definitions.
+	CreateTable(table string, definitions []string) string
+}
+
+// Kind is the class of the Go values of a column, which decides its SQL type.
+type Kind int
+
+const (
+	KindUnknown Kind = iota // Any other type, whose SQL type must be given
+	KindInt                 // Signed and unsigned integers
+	KindFloat               // Floating point numbers
+	KindBool                // Booleans
+	KindString              // Strings
+	KindBytes               // Byte slices
+	KindTime                // time.Time
+)
+
+// KindOf returns the kind of the values of type t, seeing through pointers and the sql.Null* types.
+func KindOf(t reflect.Type) Kind {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	switch t {
+	case reflect.TypeOf(time.Time{}), reflect.TypeOf(sql.NullTime{}):
+		return KindTime
+	case reflect.TypeOf(sql.NullInt64{}), reflect.TypeOf(sql.NullInt32{}), reflect.TypeOf(sql.NullInt16{}):
+		return KindInt
+	case reflect.TypeOf(sql.NullFloat64{}):
+		return KindFloat
+	case reflect.TypeOf(sql.NullBool{}):
+		return KindBool
+	case reflect.TypeOf(sql.NullString{}):
+		return KindString
+	}
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return KindInt
+	case reflect.Float32, reflect.Float64:
+		return KindFloat
+	case reflect.Bool:
+		return KindBool
+	case reflect.String:
+		return KindString
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return KindBytes
+		}
+	}
+	return KindUnknown
+}
+
+// CreateTableIfNotExists creates the table with the columns given to Init unless it exists, so the rows
+// can be loaded into a scratch table without writing its DDL. types sets the SQL type of some columns,
+// e.g. {"name": "VARCHAR(64) NOT NULL"}, the type of the others is inferred from their first non NULL
+// buffered value.
+func (b *Bulk) CreateTableIfNotExists(ctx context.Context, db Execer, types map[string]string) error {
+	if err := b.ready(); err != nil && err != ErrNoRows {
+		return err
+	}
+	tc, ok := b.dialect.(TableCreator)
+	if !ok {
+		return fmt.Errorf("ERROR: The dialect %T can't create tables", b.dialect)
+	}
+	definitions := make([]string, len(b.columns))
+	for i, c := range b.columns {
+		typ, ok := types[c]
+		if !ok {
+			kind := KindUnknown
+			for r := 0; r < b.rows && kind == KindUnknown; r++ {
+				if v := b.vals[r*b.valuesPerRow+i]; v != nil {
+					kind = KindOf(reflect.TypeOf(v))
+				}
+			}
+			if typ = tc.ColumnType(kind); kind == KindUnknown || typ == "" {
+				return fmt.Errorf("ERROR: The type of the column %v can't be inferred, set it in types", c)
+			}
+		}
+		definitions[i] = b.quote(c) + " " + typ
+	}
+	_, err := db.ExecContext(ctx, tc.CreateTable(b.quote(b.table), definitions))
+	return err
+}
+
+// StructTypes returns the SQL types of the columns for the struct v, as CreateTableIfNotExists takes them,
+// so the table can be created before buffering any row. The fields are matched to the columns as in
+// AddStructs, and their type is given with the sqltype tag, e.g. `bulk:"name" sqltype:"VARCHAR(64)"`, or
+// inferred from their Go type.
+func (b *Bulk) StructTypes(v interface{}) (map[string]string, error) {
+	t := reflect.TypeOf(v)
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("ERROR: StructTypes requires a struct, got %T", v)
+	}
+	tc, ok := b.dialect.(TableCreator)
+	if !ok {
+		return nil, fmt.Errorf("ERROR: The dialect %T can't create tables", b.dialect)
+	}
+	fields, err := b.structFields(t)
+	if err != nil {
+		return nil, err
+	}
+	types := make(map[string]string, len(fields))
+	for i, index := range fields {
+		f := t.FieldByIndex(index)
+		if typ := f.Tag.Get("sqltype"); typ != "" {
+			types[b.columns[i]] = typ
+		} else if kind := KindOf(f.Type); kind != KindUnknown {
+			types[b.columns[i]] = tc.ColumnType(kind)
+		}
+	}
+	return types, nil
+}
+
+// createTable returns a CREATE TABLE IF NOT EXISTS statement, followed by suffix.
+func createTable(table string, definitions []string, suffix string) string {
+	return "CREATE TABLE IF NOT EXISTS " + table + " (" + strings.Join(definitions, ", ") + ")" + suffix
+}
+
+func (mysqlDialect) ColumnType(kind Kind) string {
+	return [...]string{"", "BIGINT", "DOUBLE", "BOOLEAN", "TEXT", "BLOB", "DATETIME(6)"}[kind]
+}
+
+func (mysqlDialect) CreateTable(table string, definitions []string) string {
+	return createTable(table, definitions, "")
+}
+
+func (postgresDialect) ColumnType(kind Kind) string {
+	return [...]string{"", "BIGINT", "DOUBLE PRECISION", "BOOLEAN", "TEXT", "BYTEA", "TIMESTAMPTZ"}[kind]
+}
+
+func (postgresDialect) CreateTable(table string, definitions []string) string {
+	return createTable(table, definitions, "")
+}
+
+func (sqlServerDialect) ColumnType(kind Kind) string {
+	return [...]string{"", "BIGINT", "FLOAT", "BIT", "NVARCHAR(MAX)", "VARBINARY(MAX)", "DATETIME2"}[kind]
+}
+
+func (sqlServerDialect) CreateTable(table string, definitions []string) string {
+	// SQL Server has no IF NOT EXISTS for tables
+	name := strings.ReplaceAll(table, "'", "''")
+	return "IF OBJECT_ID(N'" + name + "', N'U') IS NULL CREATE TABLE " + table + " (" + strings.Join(definitions, ", ") + ")"
+}
+
+func (oracleDialect) ColumnType(kind Kind) string {
+	return [...]string{"", "NUMBER(19)", "BINARY_DOUBLE", "NUMBER(1)", "NVARCHAR2(2000)", "BLOB", "TIMESTAMP"}[kind]
+}
+
+func (oracleDialect) CreateTable(table string, definitions []string) string {
+	// IF NOT EXISTS requires Oracle 23ai
+	return createTable(table, definitions, "")
+}
+
+func (sqliteDialect) ColumnType(kind Kind) string {
+	return [...]string{"", "INTEGER", "REAL", "BOOLEAN", "TEXT", "BLOB", "DATETIME"}[kind]
+}
+
+func (sqliteDialect) CreateTable(table string, definitions []string) string {
+	return createTable(table, definitions, "")
+}
+
+func (clickHouseDialect) ColumnType(kind Kind) string {
+	return [...]string{"", "Nullable(Int64)", "Nullable(Float64)", "Nullable(Bool)", "Nullable(String)", "Nullable(String)", "Nullable(DateTime64(6))"}[kind]
+}
+
+func (clickHouseDialect) CreateTable(table string, definitions []string) string {
+	return createTable(table, definitions, " ENGINE = MergeTree ORDER BY tuple()")
+}
@@ -0,0 +1,131 @@
+package bulk
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CSVOptions configures FromCSV. A nil *CSVOptions uses the defaults.
+type CSVOptions struct {
+	Comma       rune                                         // Field delimiter, ',' by default
+	NoHeader    bool                                         // The file has no header: the fields are the columns, in order
+	Header      map[string]string                            // Maps a header name to its column. By default the names must match, ignoring the case
+	Convert     map[string]func(string) (interface{}, error) // Converts the fields of a column, which are inserted as strings by default
+	EmptyAsNull bool                                         // Insert the empty fields as NULL
+}
+
+// FromCSV reads the rows of a CSV file from r and appends them with PrepareValues, returning the number
+// of rows read. The header is used to find the field of each column, the fields not matching any
+// column are ignored. If SetAutoFlush was called, the rows are inserted while reading and the last ones
+// are flushed at the end, so files of any size can be loaded with bounded memory.
+func (b *Bulk) FromCSV(r io.Reader, opts *CSVOptions) (int, error) {
+	if opts == nil {
+		opts = &CSVOptions{}
+	}
+	cr := csv.NewReader(r)
+	if opts.Comma != 0 {
+		cr.Comma = opts.Comma
+	}
+	cr.ReuseRecord = true
+
+	// fields[i] is the index of the field holding the value of the i-th column
+	columns := b.inputColumns()
+	fields := make([]int, len(columns))
+	for i := range fields {
+		fields[i] = i
+	}
+	if !opts.NoHeader {
+		header, err := cr.Read()
+		if err != nil {
+			return 0, fmt.Errorf("ERROR: Reading the CSV header: %w", err)
+		}
+		if fields, err = b.csvFields(header, opts.Header); err != nil {
+			return 0, err
+		}
+	} else {
+		cr.FieldsPerRecord = len(columns)
+	}
+
+	rows := 0
+	for {
+		record, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return rows, fmt.Errorf("ERROR: Reading the CSV: %w", err)
+		}
+		vals := make([]interface{}, len(fields))
+		for i, f := range fields {
+			if vals[i], err = convertField(record[f], columns[i], opts); err != nil {
+				line, _ := cr.FieldPos(f)
+				return rows, fmt.Errorf("ERROR: CSV line %v, column %v: %w", line, columns[i], err)
+			}
+		}
+		if err := b.PrepareValues(vals...); err != nil {
+			return rows, err
+		}
+		rows++
+	}
+	if b.autoFlush != nil {
+		return rows, b.Flush()
+	}
+	return rows, nil
+}
+
+// csvFields returns the index of the header field of every column.
+func (b *Bulk) csvFields(header []string, names map[string]string) ([]int, error) {
+	columns := b.inputColumns()
+	fields := make([]int, len(columns))
+	for i, c := range columns {
+		fields[i] = -1
+		for j, h := range header {
+			if column, ok := names[h]; ok && column == c || !ok && strings.EqualFold(strings.TrimSpace(h), c) {
+				fields[i] = j
+				break
+			}
+		}
+		if fields[i] < 0 {
+			return nil, fmt.Errorf("ERROR: The CSV header has no field for the column %v", c)
+		}
+	}
+	return fields, nil
+}
+
+// convertField converts the field s of column as opts says.
+func convertField(s, column string, opts *CSVOptions) (interface{}, error) {
+	if s == "" && opts.EmptyAsNull {
+		return nil, nil
+	}
+	if convert, ok := opts.Convert[column]; ok {
+		return convert(s)
+	}
+	return s, nil
+}
+
+// ParseInt converts a field to an int64, it can be used in CSVOptions.Convert.
+func ParseInt(s string) (interface{}, error) {
+	return strconv.ParseInt(strings.TrimSpace(s), 10, 64)
+}
+
+// ParseFloat converts a field to a float64, it can be used in CSVOptions.Convert.
+func ParseFloat(s string) (interface{}, error) {
+	return strconv.ParseFloat(strings.TrimSpace(s), 64)
+}
+
+// ParseBool converts a field to a bool, it can be used in CSVOptions.Convert.
+func ParseBool(s string) (interface{}, error) {
+	return strconv.ParseBool(strings.TrimSpace(s))
+}
+
+// ParseTime returns a converter of the fields written with layout to time.Time, it can be used in
+// CSVOptions.Convert.
+func ParseTime(layout string) func(string) (interface{}, error) {
+	return func(s string) (interface{}, error) {
+		return time.Parse(layout, strings.TrimSpace(s))
+	}
+}
@@ -0,0 +1,94 @@
+package bulk
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFromCSV(t *testing.T) {
+	tests := []struct {
+		name     string
+		csv      string
+		opts     *CSVOptions
+		wantRows int
+		wantArgs string
+		wantErr  string
+	}{
+		{name: "header", csv: "Name,ignored,ID\na,x,1\nb,y,2\n", wantRows: 2, wantArgs: "[1 a 2 b]"},
+		{name: "no header", csv: "1,a\n2,b\n", opts: &CSVOptions{NoHeader: true}, wantRows: 2, wantArgs: "[1 a 2 b]"},
+		{name: "mapped header", csv: "key;label\n1;a\n", opts: &CSVOptions{Comma: ';', Header: map[string]string{"key": "id", "label": "name"}}, wantRows: 1, wantArgs: "[1 a]"},
+		{
+			name:     "converted",
+			csv:      "id,name\n1,\n",
+			opts:     &CSVOptions{Convert: map[string]func(string) (interface{}, error){"id": ParseInt}, EmptyAsNull: true},
+			wantRows: 1,
+			wantArgs: "[1 <nil>]",
+		},
+		{name: "missing field", csv: "id\n1\n", wantErr: "The CSV header has no field for the column name"},
+		{name: "wrong fields", csv: "1,a\n2\n", opts: &CSVOptions{NoHeader: true}, wantRows: 1, wantErr: "Reading the CSV"},
+		{
+			name:     "conversion",
+			csv:      "id,name\n1,a\nx,b\n",
+			opts:     &CSVOptions{Convert: map[string]func(string) (interface{}, error){"id": ParseInt}},
+			wantRows: 1,
+			wantErr:  "CSV line 3, column id",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := New(MySQL)
+			b.Init("t", "id", "name")
+			rows, err := b.FromCSV(strings.NewReader(tt.csv), tt.opts)
+			if rows != tt.wantRows {
+				t.Errorf("got %v rows, expected %v", rows, tt.wantRows)
+			}
+			if !checkError(t, err, tt.wantErr) {
+				return
+			}
+			statements, err := b.BuildStatements(false)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := fmt.Sprint(statements[0].Args); got != tt.wantArgs {
+				t.Errorf("got %v, expected %v", got, tt.wantArgs)
+			}
+		})
+	}
+}
+
+func TestFromCSVAutoFlush(t *testing.T) {
+	r := NewRecorder()
+	b := New(MySQL)
+	b.Init("t", "id")
+	b.SetAutoFlush(context.Background(), r.DB(), 2, false)
+	rows, err := b.FromCSV(strings.NewReader("id\n1\n2\n3\n"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The last rows are flushed at the end
+	if got := fmt.Sprint(recordedArgs(r)); rows != 3 || got != "[[1 2] [3]]" {
+		t.Errorf("got %v rows and the statements %v", rows, got)
+	}
+}
+
+func TestParseConverters(t *testing.T) {
+	tests := []struct {
+		convert func(string) (interface{}, error)
+		field   string
+		want    interface{}
+	}{
+		{ParseInt, " 12 ", int64(12)},
+		{ParseFloat, "1.5", 1.5},
+		{ParseBool, "true", true},
+		{ParseTime("2006-01-02"), "2020-01-02", time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		got, err := tt.convert(tt.field)
+		if err != nil || got != tt.want {
+			t.Errorf("got %v, %v for %q, expected %v", got, err, tt.field, tt.want)
+		}
+	}
+}
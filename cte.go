@@ -0,0 +1,80 @@
+package bulk
+
+import (
+	"fmt"
+	"strings"
+)
+
+// CTE wraps the rows of the insert statements in a common table expression, so they are inserted with
+// an INSERT ... SELECT reading it, e.g.
+//
+//	WITH "new_rows"("id", "name") AS (VALUES ($1,$2),($3,$4))
+//	INSERT INTO "users"("id", "name") SELECT DISTINCT ON ("id") "id", "name" FROM "new_rows" ON CONFLICT ...
+//
+// which Postgres needs to remove the duplicates of a statement before they reach ON CONFLICT DO UPDATE.
+// Postgres types the placeholders of a VALUES list inside a CTE as text, so the other columns need a cast,
+// either of the placeholder with SetExpression, e.g. "?::bigint", or of the column in Columns.
+type CTE struct {
+	Name     string            // Name of the CTE holding the rows, "new_rows" if empty
+	With     string            // Other CTEs written before it, e.g. "old AS (SELECT ...)", which the clauses may reference
+	Columns  map[string]string // SQL expressions of the inserted columns over the CTE, the column of the same name by default
+	Distinct []string          // Columns of a DISTINCT ON, keeping one of the rows of each key, Postgres only
+	Where    string            // Condition selecting the rows of the CTE which are inserted, empty for every row
+}
+
+// SetCTE makes Exec, and the methods built on it, wrap the rows of each statement in c, for the
+// dialects whose statements can start with WITH, such as Postgres and SQLite. The expressions of c are
+// written as given, so they must never contain user input. A nil c disables it.
+func (b *Bulk) SetCTE(c *CTE) {
+	b.cte = c
+}
+
+// withCTE returns the start and the end of the insert statements wrapping their rows in the CTE set with
+// SetCTE, given the ones of the plain insert statements.
+func (b *Bulk) withCTE(head, clause string) (string, string, error) {
+	c := b.cte
+	if c == nil {
+		return head, clause, nil
+	}
+	if _, ok := b.dialect.(Merger); ok {
+		return "", "", fmt.Errorf("ERROR: The dialect %T can't wrap the rows of its upserts in a CTE", b.dialect)
+	}
+	name := c.Name
+	if name == "" {
+		name = "new_rows"
+	}
+	if err := validateIdentifiers(append([]string{name}, c.Distinct...)); err != nil {
+		return "", "", err
+	}
+	columns := strings.Join(b.quoteAll(b.columns), ", ")
+	selected := make([]string, len(b.columns))
+	for i, col := range b.columns {
+		selected[i] = b.quote(col)
+		if e, ok := c.Columns[col]; ok {
+			selected[i] = e + " AS " + b.quote(col)
+		}
+	}
+
+	var sb strings.Builder
+	sb.WriteString("WITH ")
+	if c.With != "" {
+		sb.WriteString(c.With + ", ")
+	}
+	sb.WriteString(b.quote(name) + "(" + columns + ") AS (VALUES ")
+	newHead := sb.String()
+
+	sb.Reset()
+	sb.WriteString(") " + strings.TrimSuffix(head, "VALUES ") + "SELECT ")
+	if len(c.Distinct) > 0 {
+		sb.WriteString("DISTINCT ON (" + strings.Join(b.quoteAll(c.Distinct), ", ") + ") ")
+	}
+	sb.WriteString(strings.Join(selected, ", ") + " FROM " + b.quote(name))
+	if c.Where != "" {
+		sb.WriteString(" WHERE " + c.Where)
+	} else if clause != "" {
+		// SQLite can't parse a SELECT followed by ON CONFLICT without a WHERE
+		sb.WriteString(" WHERE 1=1")
+	}
+	sb.WriteString(clause)
+	return newHead, sb.String(), nil
+}
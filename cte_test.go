@@ -0,0 +1,85 @@
+package bulk
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCTE(t *testing.T) {
+	full := &CTE{
+		Name:     "src",
+		With:     "k AS (SELECT 1)",
+		Columns:  map[string]string{"name": "upper(name)"},
+		Distinct: []string{"id"},
+		Where:    "id > 0",
+	}
+	tests := []struct {
+		name    string
+		dialect Dialect
+		cte     *CTE
+		replace bool
+		want    string
+		wantErr string
+	}{
+		{
+			name:    "default name",
+			dialect: Postgres,
+			cte:     &CTE{},
+			want:    `WITH "new_rows"("id", "name") AS (VALUES ($1,$2)) INSERT INTO "t"("id", "name") SELECT "id", "name" FROM "new_rows"`,
+		},
+		{
+			name:    "upsert",
+			dialect: Postgres,
+			cte:     &CTE{},
+			replace: true,
+			want:    `WITH "new_rows"("id", "name") AS (VALUES ($1,$2)) INSERT INTO "t"("id", "name") SELECT "id", "name" FROM "new_rows" WHERE 1=1 ON CONFLICT ("id") DO UPDATE SET "id"=EXCLUDED."id","name"=EXCLUDED."name"`,
+		},
+		{
+			name:    "every field",
+			dialect: Postgres,
+			cte:     full,
+			replace: true,
+			want:    `WITH k AS (SELECT 1), "src"("id", "name") AS (VALUES ($1,$2)) INSERT INTO "t"("id", "name") SELECT DISTINCT ON ("id") "id", upper(name) AS "name" FROM "src" WHERE id > 0 ON CONFLICT ("id") DO UPDATE SET "id"=EXCLUDED."id","name"=EXCLUDED."name"`,
+		},
+		{
+			name:    "sqlite",
+			dialect: SQLite,
+			cte:     &CTE{Where: "id > 0"},
+			want:    `WITH "new_rows"("id", "name") AS (VALUES (?,?)) INSERT INTO "t"("id", "name") SELECT "id", "name" FROM "new_rows" WHERE id > 0`,
+		},
+		{
+			name:    "invalid name",
+			dialect: Postgres,
+			cte:     &CTE{Name: "x;"},
+			wantErr: `Invalid identifier "x;"`,
+		},
+		{
+			name:    "invalid distinct column",
+			dialect: Postgres,
+			cte:     &CTE{Distinct: []string{"id--"}},
+			wantErr: `Invalid identifier "id--"`,
+		},
+		{
+			name:    "merge dialect",
+			dialect: SQLServer,
+			cte:     &CTE{},
+			wantErr: "can't wrap the rows",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := New(tt.dialect)
+			b.Init("t", "id", "name")
+			b.SetConflictTarget("id")
+			b.SetCTE(tt.cte)
+			b.PrepareValues(1, "a")
+			got, err := buildSQL(b, tt.replace)
+			if !checkError(t, err, tt.wantErr) {
+				return
+			}
+			if want := []string{tt.want}; !reflect.DeepEqual(got, want) {
+				t.Errorf("got %q, expected %q", got, want)
+			}
+		})
+	}
+}
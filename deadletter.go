@@ -0,0 +1,137 @@
+package bulk
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+)
+
+// DeadLetterSink receives the rows which weren't inserted: those rejected by the BeforeRow hooks or the
+// lenient validation, and the RowErrors of the continue on error mode, so they can be fixed and loaded
+// again. The Values of each row are those of the first columns, which are the columns given to Init
+// followed by the constant and audit columns for the rows which failed in the database.
+type DeadLetterSink interface {
+	WriteRejects(ctx context.Context, table string, columns []string, rows []*RowError) error
+}
+
+// SetDeadLetter makes Exec, and the methods built on it, give the rows which weren't inserted to s once
+// the statements are executed, whether the load succeeded or not. Each rejected row is given once. An
+// error of s is returned by Exec, unless the load failed too. A nil s disables it.
+func (b *Bulk) SetDeadLetter(s DeadLetterSink) {
+	b.deadLetter = s
+}
+
+// sendDeadLetters gives the rows rejected since the previous call, and the row errors of result, to the
+// sink set with SetDeadLetter. It returns err, or the error of the sink if err is nil.
+func (b *Bulk) sendDeadLetters(ctx context.Context, result *Result, err error) error {
+	if b.deadLetter == nil {
+		return err
+	}
+	if b.deadLettered > len(b.rejected) {
+		b.deadLettered = len(b.rejected)
+	}
+	rows := append([]*RowError{}, b.rejected[b.deadLettered:]...)
+	rows = append(rows, result.RowErrors...)
+	b.deadLettered = len(b.rejected)
+	if len(rows) == 0 {
+		return err
+	}
+	if sinkErr := b.deadLetter.WriteRejects(ctx, b.table, b.columns, rows); sinkErr != nil && err == nil {
+		return fmt.Errorf("ERROR: The rejected rows can't be written to the dead letter sink: %w", sinkErr)
+	}
+	return err
+}
+
+// CSVDeadLetter is a DeadLetterSink writing the rows as CSV records, e.g. to a rejects file. Each record
+// holds the table, the index of the row, the error and the values of the row. The first record is a
+// header naming the fields.
+type CSVDeadLetter struct {
+	w      *csv.Writer
+	header bool
+}
+
+// NewCSVDeadLetter returns a CSVDeadLetter writing to w.
+func NewCSVDeadLetter(w io.Writer) *CSVDeadLetter {
+	return &CSVDeadLetter{w: csv.NewWriter(w)}
+}
+
+// WriteRejects writes a record per row, and flushes them.
+func (s *CSVDeadLetter) WriteRejects(ctx context.Context, table string, columns []string, rows []*RowError) error {
+	if !s.header {
+		if err := s.w.Write(append([]string{"table", "row", "error"}, columns...)); err != nil {
+			return err
+		}
+		s.header = true
+	}
+	for _, r := range rows {
+		record := []string{table, strconv.Itoa(r.Row), r.Err.Error()}
+		for _, v := range r.Values {
+			record = append(record, rejectText(v))
+		}
+		if err := s.w.Write(record); err != nil {
+			return err
+		}
+	}
+	s.w.Flush()
+	return s.w.Error()
+}
+
+// rejectText returns the text of the value v of a rejected row, empty for NULL.
+func rejectText(v interface{}) string {
+	switch v := nullValue(v).(type) {
+	case nil, missing:
+		return ""
+	case string:
+		return v
+	case []byte:
+		return string(v)
+	case time.Time:
+		return v.Format(time.RFC3339Nano)
+	case Raw:
+		return string(v)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// DeadLetterTable returns a DeadLetterSink inserting the rows into table of db with the dialect d, whose
+// columns are table_name, row_index, row_data and error_text, e.g. created as
+//
+//	CREATE TABLE load_rejects (table_name TEXT, row_index BIGINT, row_data TEXT, error_text TEXT)
+//
+// row_data is a JSON object holding the values of the row by column name.
+func DeadLetterTable(db Execer, d Dialect, table string) DeadLetterSink {
+	return &deadLetterTable{db: db, dialect: d, table: table}
+}
+
+type deadLetterTable struct {
+	db      Execer
+	dialect Dialect
+	table   string
+}
+
+func (s *deadLetterTable) WriteRejects(ctx context.Context, table string, columns []string, rows []*RowError) error {
+	b := New(s.dialect)
+	b.Init(s.table, "table_name", "row_index", "row_data", "error_text")
+	for _, r := range rows {
+		data := map[string]interface{}{}
+		for i, v := range r.Values {
+			if i < len(columns) && v != Missing {
+				data[columns[i]] = nullValue(v)
+			}
+		}
+		text, err := json.Marshal(data)
+		if err != nil {
+			return err
+		}
+		if err := b.PrepareValues(table, r.Row, string(text), r.Err.Error()); err != nil {
+			return err
+		}
+	}
+	_, err := b.Exec(ctx, s.db, false)
+	return err
+}
@@ -0,0 +1,77 @@
+package bulk
+
+import (
+	"fmt"
+	"math/big"
+)
+
+// normalizeDecimals replaces the *big.Int, *big.Float and *big.Rat values of row, which is in the buffer,
+// with their exact decimal text, e.g. "12.345", so the drivers don't reject them nor convert them to
+// float64. The database converts the text to the type of the column, such as DECIMAL or NUMERIC. A
+// *big.Float is written with the fewest digits which read back as the same value at its precision, and a
+// *big.Rat must have a finite decimal expansion, e.g. 1/8 but not 1/3. The decimal types which are a
+// driver.Valuer, such as decimal.Decimal of github.com/shopspring/decimal, are already sent as their exact
+// text by the drivers.
+func (b *Bulk) normalizeDecimals(row []interface{}) error {
+	for i, v := range row {
+		var s string
+		switch v := v.(type) {
+		case *big.Int:
+			if v == nil {
+				row[i] = nil
+				continue
+			}
+			s = v.String()
+		case *big.Float:
+			if v == nil {
+				row[i] = nil
+				continue
+			}
+			if v.IsInf() {
+				return fmt.Errorf("ERROR: The value %v of the column %v isn't a decimal number", v, b.columns[i])
+			}
+			s = v.Text('f', -1)
+		case *big.Rat:
+			if v == nil {
+				row[i] = nil
+				continue
+			}
+			digits, ok := decimalDigits(v)
+			if !ok {
+				return fmt.Errorf("ERROR: The value %v of the column %v has no exact decimal representation", v, b.columns[i])
+			}
+			s = v.FloatString(digits)
+		default:
+			continue
+		}
+		row[i] = s
+	}
+	return nil
+}
+
+// decimalDigits returns the number of decimal digits needed to write r exactly, or false if its decimal
+// expansion is infinite, which happens when its denominator has prime factors other than 2 and 5.
+func decimalDigits(r *big.Rat) (int, bool) {
+	d := new(big.Int).Set(r.Denom())
+	two, five := big.NewInt(2), big.NewInt(5)
+	m := new(big.Int)
+	count := func(p *big.Int) int {
+		n := 0
+		for {
+			q, rem := new(big.Int).QuoRem(d, p, m)
+			if rem.Sign() != 0 {
+				return n
+			}
+			d.Set(q)
+			n++
+		}
+	}
+	twos, fives := count(two), count(five)
+	if d.Cmp(big.NewInt(1)) != 0 {
+		return 0, false
+	}
+	if twos > fives {
+		return twos, true
+	}
+	return fives, true
+}
@@ -0,0 +1,60 @@
+package bulk
+
+import (
+	"math"
+	"math/big"
+	"testing"
+)
+
+func TestDecimals(t *testing.T) {
+	huge, _ := new(big.Int).SetString("123456789012345678901234567890", 10)
+	tests := []struct {
+		name    string
+		value   interface{}
+		want    interface{}
+		wantErr string
+	}{
+		{name: "int", value: huge, want: "123456789012345678901234567890"},
+		{name: "negative int", value: big.NewInt(-42), want: "-42"},
+		{name: "float", value: big.NewFloat(12.345), want: "12.345"},
+		{name: "float without fraction", value: big.NewFloat(1e6), want: "1000000"},
+		{name: "rat", value: big.NewRat(1, 8), want: "0.125"},
+		{name: "rat of a power of 10", value: big.NewRat(-12345, 100), want: "-123.45"},
+		{name: "integer rat", value: big.NewRat(6, 3), want: "2"},
+		{name: "nil int", value: (*big.Int)(nil), want: nil},
+		{name: "nil rat", value: (*big.Rat)(nil), want: nil},
+		{name: "other types", value: 1.5, want: 1.5},
+		{name: "infinite float", value: big.NewFloat(math.Inf(1)), wantErr: "isn't a decimal number"},
+		{name: "infinite expansion", value: big.NewRat(1, 3), wantErr: "has no exact decimal representation"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := New(Postgres)
+			b.Init("t", "amount")
+			err := b.PrepareValues(tt.value)
+			if !checkError(t, err, tt.wantErr) {
+				return
+			}
+			statements, err := b.BuildStatements(false)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := statements[0].Args[0]; got != tt.want {
+				t.Errorf("got %#v, expected %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecimalsRejectedRow(t *testing.T) {
+	b := New(Postgres)
+	b.Init("t", "amount")
+	b.PrepareValues(big.NewInt(1))
+	if err := b.PrepareValues(big.NewRat(1, 3)); err == nil {
+		t.Fatal("expected an error for 1/3")
+	}
+	// The failed row isn't buffered
+	if b.rows != 1 || len(b.vals) != 1 {
+		t.Errorf("got %v rows and %v values buffered, expected 1", b.rows, len(b.vals))
+	}
+}
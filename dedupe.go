@@ -0,0 +1,81 @@
+package bulk
+
+import "fmt"
+
+// DedupePolicy is the row kept by DedupeBy among the rows with the same key.
+type DedupePolicy int
+
+const (
+	KeepFirst DedupePolicy = iota // The first row added is kept
+	KeepLast                      // The last row added is kept, in its position
+)
+
+// DedupeBy makes Insert and Exec remove the buffered rows repeating the key of another row before
+// executing, keeping the one policy says, so a source with repeated keys doesn't make the batches fail
+// with a duplicate key error. The keys are the values of columns, compared by their text. Calling it with
+// no columns disables the deduplication. The removed rows are dropped from the buffer, so the row numbers
+// of Result.Committed, the errors and SetResumeRow count the kept rows: a failed load is resumed on the
+// same Bulk, or with the same rows added again, which are deduplicated the same way. The checkpoints of
+// SetCheckpoint count the received rows, including the removed ones.
+func (b *Bulk) DedupeBy(policy DedupePolicy, columns ...string) {
+	b.dedupePolicy = policy
+	b.dedupeColumns = columns
+}
+
+// dedupe removes the duplicate rows from the buffer as set with DedupeBy, returning how many were removed.
+func (b *Bulk) dedupe() (int, error) {
+	if len(b.dedupeColumns) == 0 || b.rows < 2 {
+		return 0, nil
+	}
+	keyIndex, err := b.columnIndexes(b.dedupeColumns)
+	if err != nil {
+		return 0, err
+	}
+
+	// The row kept for each key
+	kept := make(map[string]int, b.rows)
+	for r := 0; r < b.rows; r++ {
+		k := rowKey(b.vals[r*b.valuesPerRow:(r+1)*b.valuesPerRow], keyIndex)
+		if _, ok := kept[k]; !ok || b.dedupePolicy == KeepLast {
+			kept[k] = r
+		}
+	}
+	if len(kept) == b.rows {
+		return 0, nil
+	}
+
+	// The kept rows are moved to the front, in their order
+	n := 0
+	for r := 0; r < b.rows; r++ {
+		row := b.vals[r*b.valuesPerRow : (r+1)*b.valuesPerRow]
+		if kept[rowKey(row, keyIndex)] != r {
+			continue
+		}
+		copy(b.vals[n*b.valuesPerRow:], row)
+		n++
+	}
+	for i := n * b.valuesPerRow; i < len(b.vals); i++ {
+		b.vals[i] = nil
+	}
+	removed := b.rows - n
+	b.vals = b.vals[:n*b.valuesPerRow]
+	b.rows = n
+	return removed, nil
+}
+
+// columnIndexes returns the position of each of names among the columns of the statement.
+func (b *Bulk) columnIndexes(names []string) ([]int, error) {
+	indexes := make([]int, len(names))
+	for i, name := range names {
+		indexes[i] = -1
+		for j, c := range b.columns {
+			if c == name {
+				indexes[i] = j
+			}
+		}
+		if indexes[i] < 0 {
+			return nil, fmt.Errorf("ERROR: The column %v isn't inserted", name)
+		}
+	}
+	return indexes, nil
+}
@@ -0,0 +1,118 @@
+package bulk
+
+import (
+	"context"
+	"fmt"
+	"testing"
+)
+
+func TestDedupe(t *testing.T) {
+	tests := []struct {
+		name     string
+		policy   DedupePolicy
+		wantArgs string
+	}{
+		{name: "keep first", policy: KeepFirst, wantArgs: "[[1 a 2 b 3 c]]"},
+		{name: "keep last", policy: KeepLast, wantArgs: "[[2 b 1 x 3 c]]"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewRecorder()
+			b := New(MySQL)
+			b.Init("t", "id", "name")
+			b.DedupeBy(tt.policy, "id")
+			b.PrepareValues(1, "a")
+			b.PrepareValues(2, "b")
+			b.PrepareValues(1, "x")
+			b.PrepareValues(3, "c")
+			result, err := b.Exec(context.Background(), r.DB(), false)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result.Deduplicated != 1 {
+				t.Errorf("got %v rows removed, expected 1", result.Deduplicated)
+			}
+			if got := fmt.Sprint(recordedArgs(r)); got != tt.wantArgs {
+				t.Errorf("got the statements %v, expected %v", got, tt.wantArgs)
+			}
+		})
+	}
+}
+
+func TestDedupeResume(t *testing.T) {
+	r := NewRecorder()
+	r.SetResponder(func(s Statement) (int64, error) {
+		if s.Args[0] == 3 {
+			return 0, fmt.Errorf("boom")
+		}
+		return 1, nil
+	})
+	addRows := func(b *Bulk) {
+		b.Init("t", "id")
+		b.DedupeBy(KeepFirst, "id")
+		b.SetBatchLimit(1)
+		for _, id := range []int{1, 1, 2, 3, 4} {
+			b.PrepareValues(id)
+		}
+	}
+	b := New(MySQL)
+	addRows(b)
+	result, err := b.Exec(context.Background(), r.DB(), false)
+	if err == nil {
+		t.Fatal("expected the error of the row 3")
+	}
+	// The committed rows are counted without the removed one
+	if result.Committed != 2 {
+		t.Fatalf("got %v committed rows, expected 2", result.Committed)
+	}
+	r.SetResponder(nil)
+
+	// Resumed on the same Bulk
+	r.Reset()
+	b.SetResumeRow(result.Committed)
+	if err := b.Insert(r.DB(), false); err != nil {
+		t.Fatal(err)
+	}
+	if got := fmt.Sprint(recordedArgs(r)); got != "[[3] [4]]" {
+		t.Errorf("got the statements %v, expected the rows 3 and 4", got)
+	}
+
+	// Resumed with the same rows added again
+	r.Reset()
+	b = New(MySQL)
+	addRows(b)
+	b.SetResumeRow(result.Committed)
+	if err := b.Insert(r.DB(), false); err != nil {
+		t.Fatal(err)
+	}
+	if got := fmt.Sprint(recordedArgs(r)); got != "[[3] [4]]" {
+		t.Errorf("got the statements %v, expected the rows 3 and 4", got)
+	}
+}
+
+func TestDedupeCheckpoint(t *testing.T) {
+	ctx := context.Background()
+	store := NewFileCheckpointStore(t.TempDir())
+	r := NewRecorder()
+	b := New(MySQL)
+	b.Init("t", "id")
+	b.DedupeBy(KeepFirst, "id")
+	if err := b.SetCheckpoint(ctx, store, "load"); err != nil {
+		t.Fatal(err)
+	}
+	b.SetAutoFlush(ctx, r.DB(), 3, false)
+	for _, id := range []int{1, 1, 2} {
+		b.PrepareValues(id)
+	}
+	if err := b.Close(); err != nil {
+		t.Fatal(err)
+	}
+	// The removed row is received, so it isn't received again by a resumed run
+	saved, err := store.Load(ctx, "load")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if saved != 3 {
+		t.Errorf("got the checkpoint %v, expected 3", saved)
+	}
+}
@@ -0,0 +1,115 @@
+package bulk
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+)
+
+// BulkDelete deletes the rows of a table by key in bulk: it accumulates the keys and deletes them with
+// as few DELETE ... WHERE key IN (...) statements as the placeholder limit allows. Composite keys are
+// matched as row values, (k1, k2) IN ((?,?),...), which SQL Server doesn't support.
+type BulkDelete struct {
+	b     *Bulk            // Holds the keys as rows, with the key columns as columns
+	where []constantColumn // Values the deleted rows must also have, as the constant columns for Sync
+}
+
+// NewDelete returns a BulkDelete which generates statements for the given dialect.
+func NewDelete(d Dialect) *BulkDelete {
+	return &BulkDelete{b: New(d)}
+}
+
+// Init sets the table and the columns of the key, and clears the keys added so far.
+func (d *BulkDelete) Init(table string, keys ...string) {
+	d.b.Init(table, keys...)
+}
+
+// AddKey adds the key of a row to delete, with one value per key column.
+func (d *BulkDelete) AddKey(vals ...interface{}) error {
+	return d.b.PrepareValues(vals...)
+}
+
+// Reset clears the keys added so far.
+func (d *BulkDelete) Reset() {
+	d.b.Reset()
+}
+
+// SetBatchLimit sets the maximum number of placeholders per statement, see Bulk.SetBatchLimit.
+func (d *BulkDelete) SetBatchLimit(n int) {
+	d.b.SetBatchLimit(n)
+}
+
+// SetRetryPolicy makes the failed statements be retried, see Bulk.SetRetryPolicy.
+func (d *BulkDelete) SetRetryPolicy(p *RetryPolicy) {
+	d.b.SetRetryPolicy(p)
+}
+
+// SetLogger makes every executed statement be logged with l, see Bulk.SetLogger.
+func (d *BulkDelete) SetLogger(l *slog.Logger) {
+	d.b.SetLogger(l)
+}
+
+// Delete deletes the rows with the added keys from db.
+func (d *BulkDelete) Delete(db Execer) (*Result, error) {
+	return d.DeleteContext(context.Background(), db)
+}
+
+// DeleteContext works like Delete, using ctx for the statements. Result.Committed is the number of keys
+// whose rows were deleted when a statement fails.
+func (d *BulkDelete) DeleteContext(ctx context.Context, db Execer) (*Result, error) {
+	b := d.b
+	result := &Result{}
+	if err := b.ready(); err != nil {
+		return result, err
+	}
+	limit := b.batchSize() - len(d.where)
+	if limit < b.valuesPerRow {
+		return result, fmt.Errorf("ERROR: The batch limit (%v) is lower than the number of key columns (%v)", limit, b.valuesPerRow)
+	}
+	stmts := newStmtCache(db)
+	defer stmts.close()
+	for _, bt := range b.batches(limit) {
+		start := time.Now()
+		str := d.statement(bt)
+		args := b.args(bt)
+		for _, c := range d.where {
+			args = append(args, c.value)
+		}
+		res, err := b.execStatement(ctx, stmts, str, args)
+		b.logBatch(ctx, bt, str, time.Since(start), err)
+		if err != nil {
+			return result, &BatchError{Index: bt.index, FirstRow: bt.start, Rows: bt.rows(), Err: err}
+		}
+		if err := result.add(res); err != nil {
+			return result, err
+		}
+		result.Committed = bt.end
+	}
+	return result, nil
+}
+
+// statement returns the delete statement for the keys in bt.
+func (d *BulkDelete) statement(bt batch) string {
+	b := d.b
+	var sb strings.Builder
+	sb.WriteString("DELETE FROM " + b.quote(b.table) + " WHERE ")
+	if b.valuesPerRow == 1 {
+		sb.WriteString(b.quote(b.columns[0]) + " IN (")
+		for i := 0; i < bt.rows(); i++ {
+			if i > 0 {
+				sb.WriteByte(',')
+			}
+			sb.WriteString(b.dialect.Placeholder(i + 1))
+		}
+	} else {
+		sb.WriteString("(" + strings.Join(b.quoteAll(b.columns), ", ") + ") IN (")
+		b.writePlaceholders(&sb, bt)
+	}
+	sb.WriteByte(')')
+	for i, c := range d.where {
+		sb.WriteString(" AND " + b.quote(c.name) + " = " + b.dialect.Placeholder(bt.rows()*b.valuesPerRow+i+1))
+	}
+	return sb.String()
+}
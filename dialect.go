@@ -0,0 +1,354 @@
+package bulk
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Dialect describes the SQL flavour of the target database: how placeholders and identifiers are written,
+// how the upsert clause looks like and how many parameters a single statement accepts. MySQL, Postgres,
+// SQL Server, Oracle, ClickHouse, SQLite, CockroachDB and TiDB are provided, other databases can be
+// supported by implementing this interface.
+type Dialect interface {
+	// Placeholder returns the bind parameter for the n-th argument of a statement, starting at 1.
+	Placeholder(n int) string
+	// QuoteIdentifier quotes a table or column name so it can be used even if it's a reserved word.
+	QuoteIdentifier(name string) string
+	// Upsert returns the keyword starting the insert statement (e.g. "INSERT INTO") and the clause appended
+	// to it, so the rows which collide with an existing key are handled as c describes instead of failing.
+	Upsert(c *Conflict) (verb, clause string, err error)
+	// MaxParameters returns the maximum number of bind parameters allowed in a single statement.
+	MaxParameters() int
+}
+
+// RowLimiter is implemented by the dialects which limit the number of rows of an insert statement, such
+// as SQL Server. The batches never exceed that number of rows.
+type RowLimiter interface {
+	// MaxRows returns the maximum number of rows inserted by a single statement.
+	MaxRows() int
+}
+
+// Merger is implemented by the dialects whose upserts are MERGE statements, such as SQL Server and Oracle,
+// which can't be written as an insert statement followed by a clause.
+type Merger interface {
+	// Merge returns the start of the MERGE statement upserting rows into table as c describes, up to the
+	// VALUES list of the rows and ending with "VALUES ", and its end. The identifiers are already quoted.
+	Merge(table string, columns []string, c *Conflict) (head, tail string, err error)
+}
+
+// RowSelector is implemented by the dialects which can't list several rows in VALUES, such as Oracle before
+// 23ai. Their statements select the rows instead, joined with UNION ALL, in place of the VALUES list of
+// the inserts and of the MERGE source, and the first one names the columns.
+type RowSelector interface {
+	// RowSource returns the table the rows are selected from, e.g. "dual".
+	RowSource() string
+}
+
+// ConflictAction is what happens to a row which collides with an existing key.
+type ConflictAction int
+
+const (
+	DoUpdate  ConflictAction = iota // The existing row is updated
+	DoNothing                       // The inserted row is skipped
+	DoReplace                       // The existing row is deleted before inserting the new one
+)
+
+// Conflict describes how the rows colliding with an existing key are handled.
+type Conflict struct {
+	Action      ConflictAction
+	Target      []string          // Columns of the unique index targeted by ON CONFLICT or MERGE. MySQL doesn't need it.
+	Update      []string          // Columns overwritten with the values of the inserted row
+	Set         map[string]string // Columns set to a SQL expression, e.g. "counter + VALUES(counter)"
+	TargetWhere string            // Predicate of the partial unique index targeted by ON CONFLICT, empty for a full index
+	UpdateWhere string            // Condition the existing row must meet to be updated, empty to always update it
+	Columns     []string          // Columns inserted by the statement
+}
+
+// conflictTarget returns the conflict target of an ON CONFLICT clause, with the predicate of its index.
+func (c *Conflict) conflictTarget() string {
+	target := " (" + strings.Join(c.Target, ", ") + ")"
+	if c.TargetWhere != "" {
+		target += " WHERE " + c.TargetWhere
+	}
+	return target
+}
+
+// onConflict returns the ON CONFLICT clause of c for Postgres and SQLite, whose inserted row is named
+// excluded.
+func (c *Conflict) onConflict(excluded string) (string, error) {
+	if len(c.Target) == 0 && c.TargetWhere != "" {
+		return "", fmt.Errorf("ERROR: The predicate of a partial index requires a conflict target, use SetConflictTarget")
+	}
+	if c.Action == DoNothing {
+		if len(c.Target) == 0 {
+			return " ON CONFLICT DO NOTHING", nil
+		}
+		return " ON CONFLICT" + c.conflictTarget() + " DO NOTHING", nil
+	}
+	if len(c.Update) == 0 && len(c.Set) == 0 {
+		return "", errNoUpdate
+	}
+	endStr := " ON CONFLICT" + c.conflictTarget() + " DO UPDATE SET "
+	for _, v := range c.Update {
+		endStr += v + "=" + excluded + "." + v + ","
+	}
+	for _, v := range c.setColumns() {
+		endStr += v + "=" + c.Set[v] + ","
+	}
+	endStr = endStr[:len(endStr)-1]
+	if c.UpdateWhere != "" {
+		endStr += " WHERE " + c.UpdateWhere
+	}
+	return endStr, nil
+}
+
+// errNoUpdate is returned by the upserts which update no column.
+var errNoUpdate = errors.New("ERROR: No column is updated on duplicate, use SetIgnoreDuplicates to skip the duplicates")
+
+// setColumns returns the columns of c.Set sorted, so the generated clause is always the same.
+func (c *Conflict) setColumns() []string {
+	columns := make([]string, 0, len(c.Set))
+	for k := range c.Set {
+		columns = append(columns, k)
+	}
+	sort.Strings(columns)
+	return columns
+}
+
+var (
+	// MySQL uses ? placeholders, `backtick` quoting and ON DUPLICATE KEY UPDATE. It's the default dialect.
+	// MySQL8 writes the upserts with the row alias of MySQL 8.0.19 and later.
+	MySQL Dialect = mysqlDialect{}
+	// Postgres uses $1, $2, ... placeholders, "double quote" quoting and ON CONFLICT ... DO UPDATE.
+	Postgres Dialect = postgresDialect{}
+	// SQLServer uses @p1, @p2, ... placeholders, [bracket] quoting and MERGE. It allows 2100 parameters and
+	// 1000 rows per statement. SQLServerCopy loads the rows much faster.
+	SQLServer Dialect = sqlServerDialect{}
+	// Oracle uses :1, :2, ... placeholders, "double quote" quoting and MERGE. The rows of a statement are
+	// selected FROM dual, as the multi-row VALUES lists require Oracle 23ai. OracleArrayBind is faster.
+	Oracle Dialect = oracleDialect{}
+	// ClickHouse uses ? placeholders and `backtick` quoting, and doesn't support upserts. ClickHouseBatch
+	// sends the rows with the batch API of the driver instead of insert statements.
+	ClickHouse Dialect = clickHouseDialect{}
+	// SQLite uses ? placeholders, "double quote" quoting, ON CONFLICT ... DO UPDATE and INSERT OR REPLACE.
+	// It allows 999 parameters per statement, the default SQLITE_MAX_VARIABLE_NUMBER before SQLite 3.32,
+	// which SetBatchLimit can raise. As SQLite has a single writer, the batches run one after the other
+	// inside a single transaction, see SingleWriter.
+	SQLite Dialect = sqliteDialect{}
+)
+
+type mysqlDialect struct{}
+
+func (mysqlDialect) Placeholder(n int) string {
+	return "?"
+}
+
+func (mysqlDialect) QuoteIdentifier(name string) string {
+	return "`" + strings.ReplaceAll(name, "`", "``") + "`"
+}
+
+func (mysqlDialect) Upsert(c *Conflict) (string, string, error) {
+	if c.TargetWhere != "" || c.UpdateWhere != "" {
+		return "", "", fmt.Errorf("ERROR: MySQL doesn't support conditions in ON DUPLICATE KEY UPDATE")
+	}
+	switch c.Action {
+	case DoNothing:
+		return "INSERT IGNORE INTO", "", nil
+	case DoReplace:
+		return "REPLACE INTO", "", nil
+	}
+	if len(c.Update) == 0 && len(c.Set) == 0 {
+		return "", "", errNoUpdate
+	}
+	endStr := " ON DUPLICATE KEY UPDATE "
+	for _, v := range c.Update {
+		endStr += v + "=VALUES(" + v + "),"
+	}
+	for _, v := range c.setColumns() {
+		endStr += v + "=" + c.Set[v] + ","
+	}
+	return "INSERT INTO", endStr[:len(endStr)-1], nil
+}
+
+func (mysqlDialect) MaxParameters() int {
+	return 65535
+}
+
+type postgresDialect struct{}
+
+func (postgresDialect) Placeholder(n int) string {
+	return "$" + strconv.Itoa(n)
+}
+
+func (postgresDialect) QuoteIdentifier(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+func (postgresDialect) Upsert(c *Conflict) (string, string, error) {
+	if c.Action == DoReplace {
+		return "", "", fmt.Errorf("ERROR: Postgres doesn't support REPLACE INTO, use an upsert instead")
+	}
+	if c.Action == DoUpdate && len(c.Target) == 0 {
+		return "", "", fmt.Errorf("ERROR: Postgres upserts require a conflict target, use SetConflictTarget")
+	}
+	endStr, err := c.onConflict("EXCLUDED")
+	return "INSERT INTO", endStr, err
+}
+
+func (postgresDialect) MaxParameters() int {
+	return 65535
+}
+
+type sqlServerDialect struct{}
+
+func (sqlServerDialect) Placeholder(n int) string {
+	return "@p" + strconv.Itoa(n)
+}
+
+func (sqlServerDialect) QuoteIdentifier(name string) string {
+	return "[" + strings.ReplaceAll(name, "]", "]]") + "]"
+}
+
+func (sqlServerDialect) Upsert(c *Conflict) (string, string, error) {
+	return "", "", fmt.Errorf("ERROR: SQL Server doesn't support handling duplicates in insert statements, use MERGE")
+}
+
+func (sqlServerDialect) Merge(table string, columns []string, c *Conflict) (string, string, error) {
+	return merge(table, columns, c, " AS ", ";", true, false)
+}
+
+func (sqlServerDialect) MaxParameters() int {
+	return 2100
+}
+
+func (sqlServerDialect) MaxRows() int {
+	return 1000
+}
+
+type oracleDialect struct{}
+
+func (oracleDialect) Placeholder(n int) string {
+	return ":" + strconv.Itoa(n)
+}
+
+func (oracleDialect) QuoteIdentifier(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+func (oracleDialect) Upsert(c *Conflict) (string, string, error) {
+	return "", "", fmt.Errorf("ERROR: Oracle doesn't support handling duplicates in insert statements, use MERGE")
+}
+
+func (oracleDialect) Merge(table string, columns []string, c *Conflict) (string, string, error) {
+	return merge(table, columns, c, " ", "", false, true)
+}
+
+func (oracleDialect) RowSource() string {
+	return "dual"
+}
+
+func (oracleDialect) MaxParameters() int {
+	return 65535
+}
+
+type clickHouseDialect struct{}
+
+func (clickHouseDialect) Placeholder(n int) string {
+	return "?"
+}
+
+func (clickHouseDialect) QuoteIdentifier(name string) string {
+	return "`" + strings.ReplaceAll(name, "`", "``") + "`"
+}
+
+func (clickHouseDialect) Upsert(c *Conflict) (string, string, error) {
+	return "", "", fmt.Errorf("ERROR: ClickHouse doesn't support handling duplicates in insert statements")
+}
+
+func (clickHouseDialect) MaxParameters() int {
+	return 65535
+}
+
+type sqliteDialect struct{}
+
+func (sqliteDialect) Placeholder(n int) string {
+	return "?"
+}
+
+func (sqliteDialect) QuoteIdentifier(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+func (sqliteDialect) Upsert(c *Conflict) (string, string, error) {
+	switch c.Action {
+	case DoReplace:
+		return "INSERT OR REPLACE INTO", "", nil
+	case DoUpdate:
+		if len(c.Target) == 0 {
+			return "", "", fmt.Errorf("ERROR: SQLite upserts require a conflict target, use SetConflictTarget")
+		}
+	}
+	endStr, err := c.onConflict("excluded")
+	return "INSERT INTO", endStr, err
+}
+
+func (sqliteDialect) MaxParameters() int {
+	return 999
+}
+
+// merge returns the parts of a MERGE statement matching the rows, aliased s, with the rows of table,
+// aliased t, on the conflict target. as is the keyword before an alias and end terminates the statement.
+// The columns of the target aren't updated, as they are the ones matched. matchedAnd writes the condition
+// of the update in WHEN MATCHED AND, as SQL Server expects, instead of after its SET as Oracle does.
+// selected leaves out the column list of s, as the rows of a RowSelector dialect name them.
+func merge(table string, columns []string, c *Conflict, as, end string, matchedAnd, selected bool) (string, string, error) {
+	if c.Action == DoReplace {
+		return "", "", fmt.Errorf("ERROR: MERGE doesn't support REPLACE INTO, use an upsert instead")
+	}
+	if c.TargetWhere != "" {
+		return "", "", fmt.Errorf("ERROR: MERGE doesn't support the predicate of a partial index")
+	}
+	if len(c.Target) == 0 {
+		return "", "", fmt.Errorf("ERROR: MERGE requires a conflict target, use SetConflictTarget")
+	}
+	on := make([]string, len(c.Target))
+	for i, v := range c.Target {
+		on[i] = "t." + v + " = s." + v
+	}
+	tail := ")" + as + "s"
+	if !selected {
+		tail += " (" + strings.Join(columns, ", ") + ")"
+	}
+	tail += " ON (" + strings.Join(on, " AND ") + ")"
+
+	var set []string
+	if c.Action == DoUpdate {
+		for _, v := range c.Update {
+			if !contains(c.Target, v) {
+				set = append(set, "t."+v+" = s."+v)
+			}
+		}
+		for _, v := range c.setColumns() {
+			set = append(set, "t."+v+" = "+c.Set[v])
+		}
+	}
+	if len(set) > 0 {
+		switch {
+		case c.UpdateWhere == "":
+			tail += " WHEN MATCHED THEN UPDATE SET " + strings.Join(set, ", ")
+		case matchedAnd:
+			tail += " WHEN MATCHED AND (" + c.UpdateWhere + ") THEN UPDATE SET " + strings.Join(set, ", ")
+		default:
+			tail += " WHEN MATCHED THEN UPDATE SET " + strings.Join(set, ", ") + " WHERE " + c.UpdateWhere
+		}
+	}
+	values := make([]string, len(columns))
+	for i, v := range columns {
+		values[i] = "s." + v
+	}
+	tail += " WHEN NOT MATCHED THEN INSERT (" + strings.Join(columns, ", ") + ") VALUES (" + strings.Join(values, ", ") + ")" + end
+	return "MERGE INTO " + table + as + "t USING (VALUES ", tail, nil
+}
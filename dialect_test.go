@@ -0,0 +1,95 @@
+package bulk
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMerge(t *testing.T) {
+	tests := []struct {
+		name    string
+		dialect Dialect
+		setup   func(b *Bulk)
+		replace bool
+		want    string
+		wantErr string
+	}{
+		{
+			name:    "sql server",
+			dialect: SQLServer,
+			replace: true,
+			want:    "MERGE INTO [t] AS t USING (VALUES (@p1,@p2),(@p3,@p4)) AS s ([id], [name]) ON (t.[id] = s.[id]) WHEN MATCHED THEN UPDATE SET t.[name] = s.[name] WHEN NOT MATCHED THEN INSERT ([id], [name]) VALUES (s.[id], s.[name]);",
+		},
+		{
+			name:    "sql server expression",
+			dialect: SQLServer,
+			setup:   func(b *Bulk) { b.OnDuplicateUpdateExpr(map[string]string{"n": "n + 1"}) },
+			replace: true,
+			want:    "MERGE INTO [t] AS t USING (VALUES (@p1,@p2),(@p3,@p4)) AS s ([id], [name]) ON (t.[id] = s.[id]) WHEN MATCHED THEN UPDATE SET t.[name] = s.[name], t.[n] = n + 1 WHEN NOT MATCHED THEN INSERT ([id], [name]) VALUES (s.[id], s.[name]);",
+		},
+		{
+			name:    "sql server condition",
+			dialect: SQLServer,
+			setup:   func(b *Bulk) { b.OnDuplicateUpdateWhere("t.name <> 'x'") },
+			replace: true,
+			want:    "MERGE INTO [t] AS t USING (VALUES (@p1,@p2),(@p3,@p4)) AS s ([id], [name]) ON (t.[id] = s.[id]) WHEN MATCHED AND (t.name <> 'x') THEN UPDATE SET t.[name] = s.[name] WHEN NOT MATCHED THEN INSERT ([id], [name]) VALUES (s.[id], s.[name]);",
+		},
+		{
+			name:    "sql server ignore",
+			dialect: SQLServer,
+			setup:   func(b *Bulk) { b.SetIgnoreDuplicates(true) },
+			want:    "MERGE INTO [t] AS t USING (VALUES (@p1,@p2),(@p3,@p4)) AS s ([id], [name]) ON (t.[id] = s.[id]) WHEN NOT MATCHED THEN INSERT ([id], [name]) VALUES (s.[id], s.[name]);",
+		},
+		{
+			name:    "oracle",
+			dialect: Oracle,
+			replace: true,
+			want:    `MERGE INTO "t" t USING (SELECT :1 "id",:2 "name" FROM dual UNION ALL SELECT :3,:4 FROM dual) s ON (t."id" = s."id") WHEN MATCHED THEN UPDATE SET t."name" = s."name" WHEN NOT MATCHED THEN INSERT ("id", "name") VALUES (s."id", s."name")`,
+		},
+		{
+			name:    "oracle condition",
+			dialect: Oracle,
+			setup:   func(b *Bulk) { b.OnDuplicateUpdateWhere("t.name <> 'x'") },
+			replace: true,
+			want:    `MERGE INTO "t" t USING (SELECT :1 "id",:2 "name" FROM dual UNION ALL SELECT :3,:4 FROM dual) s ON (t."id" = s."id") WHEN MATCHED THEN UPDATE SET t."name" = s."name" WHERE t.name <> 'x' WHEN NOT MATCHED THEN INSERT ("id", "name") VALUES (s."id", s."name")`,
+		},
+		{
+			name:    "plain insert",
+			dialect: Oracle,
+			want:    `INSERT INTO "t"("id", "name") SELECT :1 "id",:2 "name" FROM dual UNION ALL SELECT :3,:4 FROM dual`,
+		},
+		{
+			name:    "oracle interpolated",
+			dialect: Oracle,
+			setup:   func(b *Bulk) { b.SetInterpolate(true) },
+			replace: true,
+			want:    `MERGE INTO "t" t USING (SELECT 1 "id",'a' "name" FROM dual UNION ALL SELECT 2,'b' FROM dual) s ON (t."id" = s."id") WHEN MATCHED THEN UPDATE SET t."name" = s."name" WHEN NOT MATCHED THEN INSERT ("id", "name") VALUES (s."id", s."name")`,
+		},
+		{
+			name:    "without target",
+			dialect: SQLServer,
+			setup:   func(b *Bulk) { b.SetConflictTarget() },
+			replace: true,
+			wantErr: "MERGE requires a conflict target",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := New(tt.dialect)
+			b.Init("t", "id", "name")
+			b.SetConflictTarget("id")
+			if tt.setup != nil {
+				tt.setup(b)
+			}
+			b.PrepareValues(1, "a")
+			b.PrepareValues(2, "b")
+			got, err := buildSQL(b, tt.replace)
+			if !checkError(t, err, tt.wantErr) {
+				return
+			}
+			if want := []string{tt.want}; !reflect.DeepEqual(got, want) {
+				t.Errorf("got %q, expected %q", got, want)
+			}
+		})
+	}
+}
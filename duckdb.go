@@ -0,0 +1,84 @@
+package bulk
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"strings"
+)
+
+// Appender appends rows to a table, flushing them when it's closed, such as the *duckdb.Appender of
+// github.com/marcboeker/go-duckdb.
+type Appender interface {
+	AppendRow(args ...driver.Value) error
+	Close() error
+}
+
+// DuckDBAppender returns a DuckDB Backend which loads the rows with the appender API, much faster than
+// insert statements. The appender is created by open on the driver connection, usually with:
+//
+//	func(c driver.Conn, schema, table string) (bulk.Appender, error) {
+//		return duckdb.NewAppenderFromConn(c, schema, table)
+//	}
+//
+// The schema is the part of the table name given to Init before the dot, if any. The appender fills every
+// column of the table in order, so the columns given to Init must be all of them, in the same order. The
+// db given to Insert must be a *sql.Conn or a pool such as *sql.DB; the appender doesn't run inside a
+// transaction, so SetAtomic, SetCommitEvery and SetTruncate aren't supported. Duplicates can't be handled.
+func DuckDBAppender(open func(c driver.Conn, schema, table string) (Appender, error)) Backend {
+	return duckDBAppender{open: open}
+}
+
+// duckDBAppender is the Backend of DuckDBAppender.
+type duckDBAppender struct {
+	open func(c driver.Conn, schema, table string) (Appender, error)
+}
+
+// Load appends the rows of t on a connection of db.
+func (d duckDBAppender) Load(ctx context.Context, db Execer, t *Table) (sql.Result, error) {
+	if t.Conflict != nil {
+		return nil, fmt.Errorf("ERROR: The DuckDB appender can't handle duplicates")
+	}
+	conn, ok := db.(*sql.Conn)
+	if !ok {
+		c, ok := db.(connector)
+		if !ok {
+			return nil, fmt.Errorf("ERROR: %T can't provide a connection to the DuckDB appender", db)
+		}
+		var err error
+		if conn, err = c.Conn(ctx); err != nil {
+			return nil, err
+		}
+		defer conn.Close()
+	}
+	schema, table := "", t.Name
+	if i := strings.LastIndexByte(table, '.'); i >= 0 {
+		schema, table = table[:i], table[i+1:]
+	}
+	err := conn.Raw(func(dc interface{}) error {
+		c, ok := dc.(driver.Conn)
+		if !ok {
+			return fmt.Errorf("ERROR: %T isn't a driver connection", dc)
+		}
+		a, err := d.open(c, schema, table)
+		if err != nil {
+			return err
+		}
+		args := make([]driver.Value, len(t.Columns))
+		for _, row := range t.Rows {
+			for i, v := range row {
+				args[i] = v
+			}
+			if err := a.AppendRow(args...); err != nil {
+				a.Close()
+				return err
+			}
+		}
+		return a.Close()
+	})
+	if err != nil {
+		return nil, err
+	}
+	return driver.RowsAffected(len(t.Rows)), nil
+}
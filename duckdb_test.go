@@ -0,0 +1,91 @@
+package bulk
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// rowsAppender is an Appender recording the appended rows. The row number fail fails, counting from 1.
+type rowsAppender struct {
+	rows   [][]driver.Value
+	fail   int
+	closed bool
+}
+
+func (a *rowsAppender) AppendRow(args ...driver.Value) error {
+	if len(a.rows)+1 == a.fail {
+		return errors.New("boom")
+	}
+	a.rows = append(a.rows, append([]driver.Value(nil), args...))
+	return nil
+}
+
+func (a *rowsAppender) Close() error {
+	a.closed = true
+	return nil
+}
+
+func TestDuckDBAppender(t *testing.T) {
+	tests := []struct {
+		name     string
+		fail     int
+		wantRows [][]driver.Value
+		wantErr  string
+	}{
+		{name: "appended", wantRows: [][]driver.Value{{1, "a"}, {2, nil}}},
+		{name: "failed", fail: 2, wantRows: [][]driver.Value{{1, "a"}}, wantErr: "boom"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := &rowsAppender{fail: tt.fail}
+			var schema, table string
+			b := New(Postgres)
+			b.Init("main.t", "id", "name")
+			b.SetBackend(DuckDBAppender(func(c driver.Conn, s, tb string) (Appender, error) {
+				schema, table = s, tb
+				return a, nil
+			}))
+			b.PrepareValues(1, "a")
+			b.PrepareValues(2, nil)
+			r := NewRecorder()
+			result, err := b.Exec(context.Background(), r.DB(), false)
+			if checkError(t, err, tt.wantErr) && result.RowsAffected != 2 {
+				t.Errorf("got %v rows affected, expected 2", result.RowsAffected)
+			}
+			if schema != "main" || table != "t" {
+				t.Errorf("got the table %v.%v, expected main.t", schema, table)
+			}
+			if !reflect.DeepEqual(a.rows, tt.wantRows) {
+				t.Errorf("got %v, expected %v", a.rows, tt.wantRows)
+			}
+			if !a.closed {
+				t.Error("the appender isn't closed")
+			}
+			if n := len(r.Statements()); n != 0 {
+				t.Errorf("got %v statements, expected none", n)
+			}
+		})
+	}
+}
+
+func TestDuckDBAppenderErrors(t *testing.T) {
+	open := func(c driver.Conn, s, tb string) (Appender, error) {
+		return &rowsAppender{}, nil
+	}
+	r := NewRecorder()
+	tx, err := r.DB().Begin()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tx.Rollback()
+	b := New(Postgres)
+	b.Init("t", "id")
+	b.SetBackend(DuckDBAppender(open))
+	b.PrepareValues(1)
+	checkError(t, b.Insert(tx, false), "*sql.Tx can't provide a connection to the DuckDB appender")
+	b.SetConflictTarget("id")
+	checkError(t, b.Insert(r.DB(), true), "The DuckDB appender can't handle duplicates")
+}
@@ -0,0 +1,73 @@
+package bulk
+
+import (
+	"fmt"
+	"time"
+)
+
+// Encoder converts the values of a column before they are buffered, e.g. to format a time.Time as a date.
+type Encoder func(v interface{}) (interface{}, error)
+
+// SetEncoder makes PrepareValues, and the methods built on it, convert the values of column with enc.
+// NULL values (nil) and Raw values aren't converted. A nil enc removes the encoder of the column.
+func (b *Bulk) SetEncoder(column string, enc Encoder) {
+	if b.encoders == nil {
+		b.encoders = map[string]Encoder{}
+	}
+	if enc == nil {
+		delete(b.encoders, column)
+	} else {
+		b.encoders[column] = enc
+	}
+	b.columnEncoders = nil
+}
+
+// encodeRow converts in place the values of row, which is in the buffer, with the encoders.
+func (b *Bulk) encodeRow(row []interface{}) error {
+	if len(b.encoders) == 0 {
+		return nil
+	}
+	if len(b.columnEncoders) != len(b.columns) {
+		b.columnEncoders = make([]Encoder, len(b.columns))
+		for i, c := range b.columns {
+			b.columnEncoders[i] = b.encoders[c]
+		}
+	}
+	for i, enc := range b.columnEncoders {
+		if enc == nil || row[i] == nil {
+			continue
+		}
+		if _, ok := row[i].(Raw); ok || row[i] == Missing {
+			continue
+		}
+		v, err := enc(row[i])
+		if err != nil {
+			return fmt.Errorf("ERROR: The value of the column %v can't be encoded: %w", b.columns[i], err)
+		}
+		row[i] = v
+	}
+	return nil
+}
+
+// FormatTime returns an Encoder formatting the time.Time values with layout, e.g. "2006-01-02".
+func FormatTime(layout string) Encoder {
+	return func(v interface{}) (interface{}, error) {
+		t, ok := v.(time.Time)
+		if !ok {
+			return nil, fmt.Errorf("%T isn't a time.Time", v)
+		}
+		return t.Format(layout), nil
+	}
+}
+
+// BoolAsInt is an Encoder converting the bool values to 1 and 0.
+func BoolAsInt(v interface{}) (interface{}, error) {
+	b, ok := v.(bool)
+	if !ok {
+		return nil, fmt.Errorf("%T isn't a bool", v)
+	}
+	if b {
+		return 1, nil
+	}
+	return 0, nil
+}
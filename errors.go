@@ -0,0 +1,49 @@
+package bulk
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrNotInitialized is returned when the Bulk is used before calling Init.
+	ErrNotInitialized = errors.New("ERROR: The Bulk is not initialized, Init must be called first")
+	// ErrNoRows is returned by Insert when there are no rows to insert.
+	ErrNoRows = errors.New("ERROR: There are no rows to insert")
+	// ErrValueCountMismatch is returned by PrepareValues when the number of values doesn't match the
+	// number of columns.
+	ErrValueCountMismatch = errors.New("ERROR: Inserted a wrong amount of values")
+)
+
+// BatchError is returned when the statement of a batch fails. It wraps the error of the driver, which
+// can be inspected with errors.Is and errors.As.
+type BatchError struct {
+	Index    int // Index of the batch in the load, starting at 0
+	FirstRow int // Index of the first row of the batch
+	Rows     int // Number of rows in the batch
+	Err      error
+}
+
+func (e *BatchError) Error() string {
+	return fmt.Sprintf("ERROR: Batch %v (rows %v to %v) failed: %v", e.Index, e.FirstRow, e.FirstRow+e.Rows-1, e.Err)
+}
+
+func (e *BatchError) Unwrap() error {
+	return e.Err
+}
+
+// RowError is the error of a row which couldn't be inserted, found in the continue on error mode, or which
+// was rejected by a BeforeRow hook.
+type RowError struct {
+	Row    int           // Index of the row
+	Values []interface{} // Values of the row
+	Err    error
+}
+
+func (e *RowError) Error() string {
+	return fmt.Sprintf("ERROR: Row %v failed: %v", e.Row, e.Err)
+}
+
+func (e *RowError) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,104 @@
+package bulk
+
+import (
+	"fmt"
+	"strings"
+)
+
+// SetExpression makes the VALUES entry of column the SQL expression expr instead of a placeholder, e.g.
+// "ST_GeomFromText(?, 4326)" or "POINT(?, ?)", so the spatial and computed columns can be loaded. Each ?
+// of expr is replaced with a placeholder of the dialect. The value of the column is the argument of the
+// expression if it has a single ?, and otherwise a []interface{} with an argument per ?. A Raw value is
+// written instead of the expression. The expression is written as given, so it must never contain user
+// input, nor a ? which isn't a placeholder. An empty expr removes the expression of the column.
+func (b *Bulk) SetExpression(column, expr string) {
+	if b.exprs == nil {
+		b.exprs = map[string]string{}
+	}
+	if expr == "" {
+		delete(b.exprs, column)
+	} else {
+		b.exprs[column] = expr
+	}
+	b.columnExprs = nil
+}
+
+// exprParts returns, for every column with an expression, the parts of the expression between its
+// placeholders, and nil for the other columns. It returns nil if no column has an expression.
+func (b *Bulk) exprParts() [][]string {
+	if len(b.exprs) == 0 {
+		return nil
+	}
+	if len(b.columnExprs) != len(b.columns) {
+		b.columnExprs = make([][]string, len(b.columns))
+		for i, c := range b.columns {
+			if e, ok := b.exprs[c]; ok {
+				b.columnExprs[i] = strings.Split(e, "?")
+			}
+		}
+	}
+	return b.columnExprs
+}
+
+// checkExprs checks that the values of the buffered row vals hold the arguments of the expressions of
+// their columns, making the NULL arguments explicit as normalizeNulls does.
+func (b *Bulk) checkExprs(vals []interface{}) error {
+	for i, parts := range b.exprParts() {
+		if parts == nil || i >= len(vals) || len(parts) == 2 {
+			continue
+		}
+		if _, ok := vals[i].(Raw); ok || vals[i] == Missing {
+			continue
+		}
+		args, ok := vals[i].([]interface{})
+		if !ok && len(parts) == 1 {
+			// The expression has no placeholder, its value is ignored
+			continue
+		}
+		if !ok || len(args) != len(parts)-1 {
+			return fmt.Errorf("ERROR: The expression of the column %v requires a []interface{} with %v values, got %v", b.columns[i], len(parts)-1, vals[i])
+		}
+		// The arguments are copied, so normalizing them doesn't modify the slice of the caller
+		normalized := make([]interface{}, len(args))
+		for j, a := range args {
+			normalized[j] = nullValue(a)
+		}
+		vals[i] = normalized
+	}
+	return nil
+}
+
+// exprArgs returns the arguments of the expression made of parts, given the value v of its column.
+func exprArgs(parts []string, v interface{}) []interface{} {
+	switch len(parts) {
+	case 1:
+		return nil
+	case 2:
+		return []interface{}{v}
+	}
+	args, _ := v.([]interface{})
+	return args
+}
+
+// paramsPerRow returns the maximum number of parameters bound for a row.
+func (b *Bulk) paramsPerRow() int {
+	n := b.valuesPerRow
+	for _, parts := range b.exprParts() {
+		if parts != nil {
+			n += len(parts) - 2
+		}
+	}
+	return n
+}
+
+// writeExpr writes the expression made of parts to sb, with the placeholders numbered from n+1, and
+// returns the number of the last one.
+func (b *Bulk) writeExpr(sb *strings.Builder, parts []string, n int) int {
+	sb.WriteString(parts[0])
+	for _, p := range parts[1:] {
+		n++
+		sb.WriteString(b.dialect.Placeholder(n))
+		sb.WriteString(p)
+	}
+	return n
+}
@@ -0,0 +1,67 @@
+package bulk
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSetExpression(t *testing.T) {
+	b := New(Postgres)
+	b.Init("t", "id", "geom", "pt")
+	b.SetExpression("geom", "ST_GeomFromText(?, 4326)")
+	b.SetExpression("pt", "POINT(?, ?)")
+	if err := b.PrepareValues(1, "POINT(1 2)", []interface{}{1, 2}); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.PrepareValues(2, nil, []interface{}{nil, 3}); err != nil {
+		t.Fatal(err)
+	}
+	statements, err := b.BuildStatements(false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Statement{{
+		SQL:  `INSERT INTO "t"("id", "geom", "pt") VALUES ($1,ST_GeomFromText($2, 4326),POINT($3, $4)),($5,ST_GeomFromText($6, 4326),POINT($7, $8))`,
+		Args: []interface{}{1, "POINT(1 2)", 1, 2, 2, nil, nil, 3},
+	}}
+	if !reflect.DeepEqual(statements, want) {
+		t.Errorf("got %v, expected %v", statements, want)
+	}
+
+	// The parameters of the expressions count for the batch limit
+	b.SetBatchLimit(7)
+	if statements, err = b.BuildStatements(false); err != nil || len(statements) != 2 {
+		t.Errorf("got %v statements and the error %v, expected a statement per row", len(statements), err)
+	}
+
+	b.SetExpression("pt", "")
+	b.SetExpression("geom", "")
+	b.SetBatchLimit(0)
+	sql, err := buildSQL(b, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{`INSERT INTO "t"("id", "geom", "pt") VALUES ($1,$2,$3),($4,$5,$6)`}; !reflect.DeepEqual(sql, want) {
+		t.Errorf("got %q, expected %q", sql, want)
+	}
+}
+
+func TestSetExpressionArguments(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   interface{}
+		wantErr string
+	}{
+		{name: "arguments", value: []interface{}{1, 2}},
+		{name: "too few", value: []interface{}{1}, wantErr: "The expression of the column pt requires a []interface{} with 2 values, got [1]"},
+		{name: "not a slice", value: 1, wantErr: "The expression of the column pt requires a []interface{} with 2 values, got 1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := New(MySQL)
+			b.Init("t", "id", "pt")
+			b.SetExpression("pt", "POINT(?, ?)")
+			checkError(t, b.PrepareValues(1, tt.value), tt.wantErr)
+		})
+	}
+}
@@ -0,0 +1,83 @@
+package bulk
+
+import (
+	"context"
+	"fmt"
+)
+
+// Feed appends the rows received from rows until it's closed, inserting them in the background into the
+// database set with SetAutoFlush while the next ones are received. Once a batch waits to be inserted and
+// the following one is full, Feed stops receiving until the insert ends, so the memory used is bounded.
+// It returns when every row is inserted, or on the first error, in which case it stops receiving: the
+// producers should also stop when ctx is done, cancelling it after Feed returns.
+func (b *Bulk) Feed(ctx context.Context, rows <-chan []interface{}) error {
+	if b.autoFlush == nil {
+		return fmt.Errorf("ERROR: Feed requires SetAutoFlush to be called first")
+	}
+	if err := b.ready(); err != nil && err != ErrNoRows {
+		return err
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// The writer inserts the chunks of rows, only it modifies the Bulk
+	chunks := make(chan [][]interface{}, 1)
+	done := make(chan error, 1)
+	go func() {
+		var err error
+		for chunk := range chunks {
+			if err == nil {
+				if err = b.flushRows(ctx, chunk); err != nil {
+					cancel()
+				}
+			}
+		}
+		done <- err
+	}()
+
+	size := b.flushThreshold()
+	chunk := make([][]interface{}, 0, size)
+	send := func() bool {
+		select {
+		case chunks <- chunk:
+			chunk = make([][]interface{}, 0, size)
+			return true
+		case <-ctx.Done():
+			return false
+		}
+	}
+read:
+	for {
+		select {
+		case row, ok := <-rows:
+			if !ok {
+				if len(chunk) > 0 {
+					send()
+				}
+				break read
+			}
+			if chunk = append(chunk, row); len(chunk) == size && !send() {
+				break read
+			}
+		case <-ctx.Done():
+			break read
+		}
+	}
+	close(chunks)
+
+	if err := <-done; err != nil {
+		return err
+	}
+	return ctx.Err()
+}
+
+// flushRows appends chunk to the buffered rows and inserts them.
+func (b *Bulk) flushRows(ctx context.Context, chunk [][]interface{}) error {
+	defer b.lockInterval()()
+	for _, row := range chunk {
+		if err := b.appendRow(row); err != nil {
+			return err
+		}
+	}
+	return b.flush(ctx)
+}
@@ -0,0 +1,184 @@
+package bulk
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// autoFlush holds the configuration set with SetAutoFlush.
+type autoFlush struct {
+	ctx                context.Context
+	db                 Execer
+	rows               int
+	replaceOnDuplicate bool
+}
+
+// SetAutoFlush enables the streaming mode: once rows rows are buffered, PrepareValues inserts them into
+// db and clears the buffer, so the memory used doesn't grow with the size of the load. If rows is 0, the
+// rows are flushed as soon as they fill a whole batch. ctx is used by every automatic flush.
+// Close must be called after the last PrepareValues to insert the remaining rows and release the
+// statements prepared by the flushes. If a flush fails, the rows it committed are removed from the buffer,
+// so the next flush only inserts the others.
+func (b *Bulk) SetAutoFlush(ctx context.Context, db Execer, rows int, replaceOnDuplicate bool) {
+	b.autoFlush = &autoFlush{ctx: ctx, db: db, rows: rows, replaceOnDuplicate: replaceOnDuplicate}
+}
+
+// Flush inserts the buffered rows into the database configured with SetAutoFlush and clears the buffer.
+func (b *Bulk) Flush() error {
+	if b.autoFlush == nil {
+		return fmt.Errorf("ERROR: Flush requires SetAutoFlush to be called first")
+	}
+	defer b.lockInterval()()
+	if err := b.intervalErr(); err != nil {
+		return err
+	}
+	return b.flush(b.autoFlush.ctx)
+}
+
+// FlushContext works like Flush, but uses ctx instead of the context given to SetAutoFlush.
+func (b *Bulk) FlushContext(ctx context.Context) error {
+	if b.autoFlush == nil {
+		return fmt.Errorf("ERROR: Flush requires SetAutoFlush to be called first")
+	}
+	defer b.lockInterval()()
+	if err := b.intervalErr(); err != nil {
+		return err
+	}
+	return b.flush(ctx)
+}
+
+// flush inserts and clears the buffered rows.
+func (b *Bulk) flush(ctx context.Context) error {
+	if b.rows == 0 {
+		return nil
+	}
+	if b.truncate {
+		return fmt.Errorf("ERROR: The streaming mode can't be combined with SetTruncate")
+	}
+	// Every flush inserts the same full batches, so their statements are kept until Close
+	if b.flushStmts == nil {
+		b.flushStmts = newStmtCache(b.autoFlush.db)
+	}
+	if result, err := b.execWith(ctx, b.flushStmts, b.autoFlush.replaceOnDuplicate); err != nil {
+		// The committed rows aren't inserted again by the next flush
+		if result != nil && result.Committed > b.resumeRow {
+			b.dropRows(result.Committed)
+		}
+		return err
+	}
+	// The rows to skip which weren't in this flush are in the next ones
+	if b.resumeRow > b.rows {
+		b.resumeRow -= b.rows
+	} else {
+		b.resumeRow = 0
+	}
+	b.clearValues()
+	return nil
+}
+
+// dropRows removes the first n buffered rows, which were inserted, so the buffer starts with the next one.
+func (b *Bulk) dropRows(n int) {
+	if n > b.rows {
+		n = b.rows
+	}
+	kept := copy(b.vals, b.vals[n*b.valuesPerRow:])
+	for i := kept; i < len(b.vals); i++ {
+		b.vals[i] = nil
+	}
+	b.vals = b.vals[:kept]
+	b.rows -= n
+	b.resumeRow = 0
+}
+
+// flushThreshold returns the number of buffered rows which triggers an automatic flush.
+func (b *Bulk) flushThreshold() int {
+	if b.autoFlush.rows > 0 {
+		return b.autoFlush.rows
+	}
+	if rows := b.batchSize() / b.valuesPerRow; rows > 0 {
+		return rows
+	}
+	return 1
+}
+
+// intervalFlusher flushes the buffered rows periodically, see SetFlushInterval.
+type intervalFlusher struct {
+	mu   sync.Mutex // Held while the Bulk is used, by a periodic flush or by a call
+	stop chan struct{}
+	done chan struct{}
+	err  error // Error of the last periodic flush, returned by the next call
+}
+
+// SetFlushInterval makes the streaming mode also flush the buffered rows every d, so a slow trickle of
+// rows doesn't stay in memory for long. It requires SetAutoFlush to be called first, and a zero d disables
+// it. The flushes run in the background, so meanwhile the Bulk can only be used to add rows, with
+// PrepareValues or the methods built on it, and with Flush and Close, which stops the periodic flushes
+// and inserts the remaining rows. An error of a periodic flush is returned by the next of these calls.
+func (b *Bulk) SetFlushInterval(d time.Duration) error {
+	if b.autoFlush == nil {
+		return fmt.Errorf("ERROR: SetFlushInterval requires SetAutoFlush to be called first")
+	}
+	if err := b.stopInterval(); err != nil {
+		return err
+	}
+	if d <= 0 {
+		return nil
+	}
+	f := &intervalFlusher{stop: make(chan struct{}), done: make(chan struct{})}
+	b.interval = f
+	ctx := b.autoFlush.ctx
+	go func() {
+		defer close(f.done)
+		t := time.NewTicker(d)
+		defer t.Stop()
+		for {
+			select {
+			case <-f.stop:
+				return
+			case <-ctx.Done():
+				return
+			case <-t.C:
+				f.mu.Lock()
+				if err := b.flush(ctx); err != nil {
+					f.err = err
+				}
+				f.mu.Unlock()
+			}
+		}
+	}()
+	return nil
+}
+
+// lockInterval keeps the periodic flushes from running until the returned function is called.
+func (b *Bulk) lockInterval() func() {
+	f := b.interval
+	if f == nil {
+		return func() {}
+	}
+	f.mu.Lock()
+	return f.mu.Unlock
+}
+
+// intervalErr returns and clears the error of the last periodic flush. lockInterval must be held.
+func (b *Bulk) intervalErr() error {
+	if b.interval == nil {
+		return nil
+	}
+	err := b.interval.err
+	b.interval.err = nil
+	return err
+}
+
+// stopInterval stops the periodic flushes, returning the error of the last one.
+func (b *Bulk) stopInterval() error {
+	f := b.interval
+	if f == nil {
+		return nil
+	}
+	close(f.stop)
+	<-f.done
+	b.interval = nil
+	return f.err
+}
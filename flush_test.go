@@ -0,0 +1,94 @@
+package bulk
+
+import (
+	"context"
+	"fmt"
+	"testing"
+)
+
+// recordedArgs returns the arguments of the recorded statements which aren't transaction statements.
+func recordedArgs(r *Recorder) [][]interface{} {
+	var args [][]interface{}
+	for _, s := range r.Statements() {
+		if s.SQL != "BEGIN" && s.SQL != "COMMIT" && s.SQL != "ROLLBACK" {
+			args = append(args, s.Args)
+		}
+	}
+	return args
+}
+
+func TestAutoFlush(t *testing.T) {
+	tests := []struct {
+		name  string
+		rows  int // Rows of each automatic flush
+		added int
+		want  string // Arguments of the recorded statements, once closed
+	}{
+		{name: "whole flushes", rows: 2, added: 4, want: "[[0 1] [2 3]]"},
+		{name: "remaining rows", rows: 2, added: 5, want: "[[0 1] [2 3] [4]]"},
+		{name: "single flush on close", rows: 10, added: 3, want: "[[0 1 2]]"},
+		{name: "batch size", rows: 0, added: 7, want: "[[0 1 2] [3 4 5] [6]]"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewRecorder()
+			b := New(Postgres)
+			b.Init("t", "id")
+			b.SetBatchLimit(3)
+			b.SetAutoFlush(context.Background(), r.DB(), tt.rows, false)
+			for i := 0; i < tt.added; i++ {
+				if err := b.PrepareValues(i); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err := b.Close(); err != nil {
+				t.Fatal(err)
+			}
+			if got := fmt.Sprint(recordedArgs(r)); got != tt.want {
+				t.Errorf("got %v, expected %v", got, tt.want)
+			}
+			if b.rows != 0 {
+				t.Errorf("got %v rows buffered after Close, expected 0", b.rows)
+			}
+		})
+	}
+}
+
+func TestAutoFlushFailure(t *testing.T) {
+	r := NewRecorder()
+	failed := false
+	r.SetResponder(func(s Statement) (int64, error) {
+		if !failed && s.Args[0] == 1 {
+			failed = true
+			return 0, fmt.Errorf("boom")
+		}
+		return int64(len(s.Args)), nil
+	})
+	b := New(Postgres)
+	b.Init("t", "id")
+	b.SetBatchLimit(1)
+	b.SetAutoFlush(context.Background(), r.DB(), 3, false)
+	b.PrepareValues(0)
+	b.PrepareValues(1)
+	if err := b.PrepareValues(2); err == nil {
+		t.Fatal("expected the error of the failed flush")
+	}
+	// The row committed by the failed flush isn't inserted again
+	if b.rows != 2 {
+		t.Fatalf("got %v rows buffered, expected the 2 which weren't inserted", b.rows)
+	}
+	if err := b.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := fmt.Sprint(recordedArgs(r)), "[[0] [1] [1] [2]]"; got != want {
+		t.Errorf("got %v, expected %v", got, want)
+	}
+}
+
+func TestFlushWithoutAutoFlush(t *testing.T) {
+	b := New(Postgres)
+	b.Init("t", "id")
+	if err := b.Flush(); err == nil {
+		t.Error("expected an error without SetAutoFlush")
+	}
+}
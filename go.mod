@@ -1,5 +1,5 @@
 module github.com/daniloor/bulk
 
-go 1.17
+go 1.21
 
 require github.com/daniloor/helper v0.0.0-20211203131449-e6825e238fb3
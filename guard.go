@@ -0,0 +1,162 @@
+package bulk
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// UTF8Action is what a StringGuard does with the strings which aren't valid UTF-8.
+type UTF8Action int
+
+const (
+	UTF8Reject   UTF8Action = iota // The row fails the validation
+	UTF8Replace                    // Every invalid sequence is replaced with U+FFFD
+	UTF8Truncate                   // The string is cut before its first invalid sequence
+)
+
+// StringGuard checks the string values of a column, so a string the column can't store makes its row
+// fail the validation, or is fixed, instead of failing the whole batch with an error of the driver.
+type StringGuard struct {
+	MaxLength int        // Maximum number of characters, or bytes if InBytes is set, 0 for no limit
+	InBytes   bool       // MaxLength counts bytes, e.g. for the VARCHAR columns of SQL Server
+	Truncate  bool       // Cut the longer strings at MaxLength instead of rejecting their row
+	UTF8      UTF8Action // Handling of the invalid UTF-8
+}
+
+// SetStringGuard checks the string values of columns, or of every column if none is given, with g. The
+// rows it rejects are handled like those failing the validators, see SetLenientValidation. The columns
+// given override the guard of every column, and g is copied. A nil g removes the guard.
+func (b *Bulk) SetStringGuard(g *StringGuard, columns ...string) {
+	if b.guards == nil {
+		b.guards = map[string]StringGuard{}
+	}
+	if len(columns) == 0 {
+		columns = []string{""}
+	}
+	for _, c := range columns {
+		if g == nil {
+			delete(b.guards, c)
+		} else {
+			b.guards[c] = *g
+		}
+	}
+	b.columnGuards = nil
+}
+
+// stringGuards returns the guard of every column by position, nil for the columns without one.
+func (b *Bulk) stringGuards() []*StringGuard {
+	if len(b.guards) == 0 {
+		return nil
+	}
+	if len(b.columnGuards) != len(b.columns) {
+		b.columnGuards = make([]*StringGuard, len(b.columns))
+		for i, c := range b.columns {
+			g, ok := b.guards[c]
+			if !ok {
+				g, ok = b.guards[""]
+			}
+			if ok {
+				b.columnGuards[i] = &g
+			}
+		}
+	}
+	return b.columnGuards
+}
+
+// check returns why s is rejected by g, if it is.
+func (g *StringGuard) check(s string) error {
+	valid := utf8.ValidString(s)
+	if !valid && g.UTF8 == UTF8Reject {
+		return fmt.Errorf("the string isn't valid UTF-8")
+	}
+	if g.MaxLength <= 0 || g.Truncate {
+		return nil
+	}
+	s = g.fixUTF8(s, valid)
+	if g.InBytes {
+		if len(s) > g.MaxLength {
+			return fmt.Errorf("the string has %v bytes, more than %v", len(s), g.MaxLength)
+		}
+	} else if n := utf8.RuneCountInString(s); n > g.MaxLength {
+		return fmt.Errorf("the string has %v characters, more than %v", n, g.MaxLength)
+	}
+	return nil
+}
+
+// fix returns s fixed as g describes, once it passed check.
+func (g *StringGuard) fix(s string) string {
+	s = g.fixUTF8(s, utf8.ValidString(s))
+	if g.MaxLength <= 0 || !g.Truncate {
+		return s
+	}
+	if g.InBytes {
+		if len(s) <= g.MaxLength {
+			return s
+		}
+		// A character isn't cut in the middle
+		n := g.MaxLength
+		for n > 0 && !utf8.RuneStart(s[n]) {
+			n--
+		}
+		return s[:n]
+	}
+	n := 0
+	for i := range s {
+		if n == g.MaxLength {
+			return s[:i]
+		}
+		n++
+	}
+	return s
+}
+
+// fixUTF8 returns s with its invalid UTF-8 handled as g describes, unless it's valid.
+func (g *StringGuard) fixUTF8(s string, valid bool) string {
+	if valid {
+		return s
+	}
+	switch g.UTF8 {
+	case UTF8Replace:
+		return strings.ToValidUTF8(s, "\uFFFD")
+	case UTF8Truncate:
+		for i, r := range s {
+			if r == utf8.RuneError {
+				if _, size := utf8.DecodeRuneInString(s[i:]); size == 1 {
+					return s[:i]
+				}
+			}
+		}
+	}
+	return s
+}
+
+// checkStrings returns the failures of the guards for the values vals of the columns given to Init.
+func (b *Bulk) checkStrings(vals []interface{}) []error {
+	var errs []error
+	columns := b.inputColumns()
+	for i, g := range b.stringGuards() {
+		if g == nil || i >= len(columns) {
+			continue
+		}
+		if s, ok := nullValue(vals[i]).(string); ok {
+			if err := g.check(s); err != nil {
+				errs = append(errs, fmt.Errorf("column %v: %w", columns[i], err))
+			}
+		}
+	}
+	return errs
+}
+
+// fixStrings fixes the strings of row, which is in the buffer and whose NULL values are explicit, as the
+// guards describe.
+func (b *Bulk) fixStrings(row []interface{}) {
+	for i, g := range b.stringGuards() {
+		if g == nil || i >= len(row) {
+			continue
+		}
+		if s, ok := row[i].(string); ok {
+			row[i] = g.fix(s)
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package bulk
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestStringGuard(t *testing.T) {
+	tests := []struct {
+		name    string
+		guard   StringGuard
+		value   string
+		want    string
+		wantErr string
+	}{
+		{name: "short enough", guard: StringGuard{MaxLength: 3}, value: "abc", want: "abc"},
+		{name: "too long", guard: StringGuard{MaxLength: 3}, value: "abcd", wantErr: "the string has 4 characters, more than 3"},
+		{name: "characters", guard: StringGuard{MaxLength: 3}, value: "héé", want: "héé"},
+		{name: "bytes", guard: StringGuard{MaxLength: 3, InBytes: true}, value: "héé", wantErr: "the string has 5 bytes, more than 3"},
+		{name: "truncated", guard: StringGuard{MaxLength: 3, Truncate: true}, value: "abcdef", want: "abc"},
+		{name: "truncated characters", guard: StringGuard{MaxLength: 2, Truncate: true}, value: "héé", want: "hé"},
+		{name: "truncated bytes", guard: StringGuard{MaxLength: 2, InBytes: true, Truncate: true}, value: "héé", want: "h"},
+		{name: "invalid UTF-8", guard: StringGuard{}, value: "a\xffb", wantErr: "isn't valid UTF-8"},
+		{name: "replaced UTF-8", guard: StringGuard{UTF8: UTF8Replace}, value: "a\xffb", want: "a�b"},
+		{name: "truncated UTF-8", guard: StringGuard{UTF8: UTF8Truncate}, value: "a\xffb", want: "a"},
+		{name: "replacement character kept", guard: StringGuard{UTF8: UTF8Truncate}, value: "a�b\xff", want: "a�b"},
+		{name: "length after the fix", guard: StringGuard{MaxLength: 2, UTF8: UTF8Truncate}, value: "ab\xffcd", want: "ab"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := New(Postgres)
+			b.Init("t", "s")
+			b.SetStringGuard(&tt.guard)
+			err := b.PrepareValues(tt.value)
+			if tt.wantErr != "" {
+				var ve *ValidationError
+				if !errors.As(err, &ve) {
+					t.Fatalf("got the error %v, expected a *ValidationError", err)
+				}
+			}
+			if !checkError(t, err, tt.wantErr) {
+				return
+			}
+			statements, err := b.BuildStatements(false)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := statements[0].Args[0]; got != tt.want {
+				t.Errorf("got %q, expected %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStringGuardColumns(t *testing.T) {
+	b := New(Postgres)
+	b.Init("t", "a", "b", "c")
+	b.SetStringGuard(&StringGuard{MaxLength: 1, Truncate: true})
+	b.SetStringGuard(&StringGuard{MaxLength: 2, Truncate: true}, "b")
+	b.SetLenientValidation(true)
+	b.PrepareValues("xyz", "xyz", "xyz")
+	// The guard of c overrides the one of every column, so its row is rejected
+	b.SetStringGuard(&StringGuard{MaxLength: 1}, "c")
+	if err := b.PrepareValues("x", "x", "xyz"); err != nil {
+		t.Fatal(err)
+	}
+	statements, err := b.BuildStatements(false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []interface{}{"x", "xy", "x"}
+	if got := statements[0].Args; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %q, expected %q", got, want)
+	}
+	if len(b.Rejected()) != 1 || b.Rejected()[0].Row != 1 {
+		t.Errorf("got the rejected rows %v, expected the row 1", b.Rejected())
+	}
+}
+
+func TestStringGuardTimeLayout(t *testing.T) {
+	b := New(Postgres)
+	b.Init("t", "s", "at")
+	b.SetStringGuard(&StringGuard{MaxLength: 3, Truncate: true})
+	b.SetTimeZone(time.UTC, "2006-01-02 15:04:05", "at")
+	b.PrepareValues("abcdef", time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC))
+	statements, err := b.BuildStatements(false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The times formatted with a layout aren't truncated by the guard
+	if got := statements[0].Args; got[0] != "abc" || got[1] != "2020-01-02 03:04:05" {
+		t.Errorf("got %q, expected [abc 2020-01-02 03:04:05]", got)
+	}
+}
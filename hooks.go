@@ -0,0 +1,24 @@
+package bulk
+
+import "time"
+
+// BatchInfo describes a batch to the progress hooks.
+type BatchInfo struct {
+	Index    int           // Index of the batch in the load, starting at 0
+	FirstRow int           // Index of the first row of the batch
+	Rows     int           // Number of rows in the batch
+	Duration time.Duration // Time spent executing the batch, including retries. Only set in OnBatchDone
+	Err      error         // Error of the batch, nil if it succeeded. Only set in OnBatchDone
+}
+
+// OnBatchStart sets a hook called before executing each batch, e.g. to report the progress of a long
+// load. With SetParallelism, the hook is called from several goroutines at the same time.
+func (b *Bulk) OnBatchStart(fn func(BatchInfo)) {
+	b.onBatchStart = fn
+}
+
+// OnBatchDone sets a hook called after executing each batch, with its duration and error. With
+// SetParallelism, the hook is called from several goroutines at the same time.
+func (b *Bulk) OnBatchDone(fn func(BatchInfo)) {
+	b.onBatchDone = fn
+}
@@ -0,0 +1,67 @@
+package bulk
+
+import (
+	"fmt"
+	"strings"
+)
+
+// SetQuoteIdentifiers enables or disables the quoting of the table and column names with the
+// QuoteIdentifier of the dialect, which is enabled by default so reserved words like order or group
+// can be used. It must be called before Init.
+func (b *Bulk) SetQuoteIdentifiers(quote bool) {
+	b.rawIdentifiers = !quote
+}
+
+// validateIdentifier returns an error if name can't be used as a table or column name. Quotes, semicolons,
+// comments and control characters are rejected, since names are written in the statements as given.
+func validateIdentifier(name string) error {
+	if name == "" {
+		return fmt.Errorf("ERROR: Empty identifier")
+	}
+	if strings.ContainsAny(name, "`\"'[];\\") || strings.Contains(name, "--") || strings.Contains(name, "/*") {
+		return fmt.Errorf("ERROR: Invalid identifier %q", name)
+	}
+	for _, r := range name {
+		if r < ' ' || r == 0x7f {
+			return fmt.Errorf("ERROR: Invalid identifier %q", name)
+		}
+	}
+	for _, part := range strings.Split(name, ".") {
+		if part == "" {
+			return fmt.Errorf("ERROR: Invalid identifier %q", name)
+		}
+	}
+	return nil
+}
+
+// quote returns name quoted for the dialect, unless quoting was disabled. Qualified names like
+// schema.table are quoted part by part.
+func (b *Bulk) quote(name string) string {
+	if b.rawIdentifiers {
+		return name
+	}
+	parts := strings.Split(name, ".")
+	for i, p := range parts {
+		parts[i] = b.dialect.QuoteIdentifier(p)
+	}
+	return strings.Join(parts, ".")
+}
+
+// quoteAll returns the names quoted with quote.
+func (b *Bulk) quoteAll(names []string) []string {
+	quoted := make([]string, len(names))
+	for i, n := range names {
+		quoted[i] = b.quote(n)
+	}
+	return quoted
+}
+
+// validateIdentifiers returns the first error found validating names.
+func validateIdentifiers(names []string) error {
+	for _, n := range names {
+		if err := validateIdentifier(n); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,85 @@
+package bulk
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+// Select describes the rows of an INSERT ... SELECT statement, copied in the database from another table.
+type Select struct {
+	From    string            // Source table, quoted like the table of the Bulk
+	Columns map[string]string // SQL expressions of the inserted columns, the source column of the same name by default
+	Where   string            // Condition selecting the source rows, with a ? for each of Args, empty for every row
+	Args    []interface{}     // Arguments of Where
+}
+
+// InsertSelect copies the rows selected by s into the table of the Bulk, as the columns given to Init,
+// with a single INSERT ... SELECT statement, or a MERGE for the dialects implementing Merger. The
+// duplicates are handled as for Exec with replaceOnDuplicate. The buffered rows aren't inserted. The
+// expressions of s are written as given, so they must never contain user input. With SetInterpolate, the
+// arguments of s are written into the statement. The CTE set with SetCTE isn't used, as the rows aren't
+// given as a VALUES list.
+func (b *Bulk) InsertSelect(ctx context.Context, db Execer, s *Select, replaceOnDuplicate bool) (sql.Result, error) {
+	st, err := b.InsertSelectStatement(s, replaceOnDuplicate)
+	if err != nil {
+		return nil, err
+	}
+	return db.ExecContext(ctx, st.SQL, st.Args...)
+}
+
+// InsertSelectStatement returns the statement InsertSelect would execute, without executing it.
+func (b *Bulk) InsertSelectStatement(s *Select, replaceOnDuplicate bool) (Statement, error) {
+	if b.initErr != nil {
+		return Statement{}, b.initErr
+	}
+	if b.table == "" {
+		return Statement{}, ErrNotInitialized
+	}
+	if err := validateIdentifier(s.From); err != nil {
+		return Statement{}, err
+	}
+	for c := range s.Columns {
+		if !contains(b.columns, c) {
+			return Statement{}, fmt.Errorf("ERROR: The column %v of the select isn't inserted", c)
+		}
+	}
+	head, clause, err := b.conflictClause(replaceOnDuplicate)
+	if err != nil {
+		return Statement{}, err
+	}
+	head = b.withHints(head)
+	head, clause = b.withComment(head, clause)
+
+	selected := make([]string, len(b.columns))
+	for i, c := range b.columns {
+		selected[i] = b.quote(c)
+		if e, ok := s.Columns[c]; ok {
+			selected[i] = e + " AS " + b.quote(c)
+		}
+	}
+	var sb strings.Builder
+	sb.WriteString("SELECT " + strings.Join(selected, ", ") + " FROM " + b.quote(s.From))
+	if s.Where != "" {
+		parts := strings.Split(s.Where, "?")
+		if len(parts)-1 != len(s.Args) {
+			return Statement{}, fmt.Errorf("ERROR: The condition of the select has %v placeholders, but %v arguments are given", len(parts)-1, len(s.Args))
+		}
+		sb.WriteString(" WHERE ")
+		if !b.interpolate {
+			b.writeExpr(&sb, parts, 0)
+		} else if err := b.interpolateExpr(&sb, parts, s.Args); err != nil {
+			return Statement{}, err
+		}
+	} else if clause != "" {
+		// SQLite can't parse a SELECT followed by ON CONFLICT without a WHERE
+		sb.WriteString(" WHERE 1=1")
+	}
+	// The VALUES list of the insert is replaced by the selected rows
+	str := strings.TrimSuffix(head, "VALUES ") + selectRows(sb.String(), clause)
+	if b.interpolate {
+		return Statement{SQL: str}, nil
+	}
+	return Statement{SQL: str, Args: s.Args}, nil
+}
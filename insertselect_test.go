@@ -0,0 +1,121 @@
+package bulk
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestInsertSelectStatement(t *testing.T) {
+	filtered := &Select{
+		From:    "s",
+		Columns: map[string]string{"name": "lower(n)"},
+		Where:   "id > ? AND n <> ?",
+		Args:    []interface{}{3, "x"},
+	}
+	tests := []struct {
+		name        string
+		dialect     Dialect
+		s           *Select
+		replace     bool
+		interpolate bool
+		want        Statement
+		wantErr     string
+	}{
+		{
+			name:    "every row",
+			dialect: Postgres,
+			s:       &Select{From: "s"},
+			want:    Statement{SQL: `INSERT INTO "t"("id", "name") SELECT "id", "name" FROM "s"`},
+		},
+		{
+			name:    "expressions and condition",
+			dialect: Postgres,
+			s:       filtered,
+			want:    Statement{SQL: `INSERT INTO "t"("id", "name") SELECT "id", lower(n) AS "name" FROM "s" WHERE id > $1 AND n <> $2`, Args: []interface{}{3, "x"}},
+		},
+		{
+			name:        "interpolated condition",
+			dialect:     Postgres,
+			s:           &Select{From: "s", Where: "n = ?", Args: []interface{}{"o'k"}},
+			interpolate: true,
+			want:        Statement{SQL: `INSERT INTO "t"("id", "name") SELECT "id", "name" FROM "s" WHERE n = 'o''k'`},
+		},
+		{
+			name:    "postgres upsert",
+			dialect: Postgres,
+			s:       &Select{From: "s"},
+			replace: true,
+			want:    Statement{SQL: `INSERT INTO "t"("id", "name") SELECT "id", "name" FROM "s" WHERE 1=1 ON CONFLICT ("id") DO UPDATE SET "id"=EXCLUDED."id","name"=EXCLUDED."name"`},
+		},
+		{
+			name:    "mysql upsert",
+			dialect: MySQL,
+			s:       filtered,
+			replace: true,
+			want:    Statement{SQL: "INSERT INTO `t`(`id`, `name`) SELECT `id`, lower(n) AS `name` FROM `s` WHERE id > ? AND n <> ? ON DUPLICATE KEY UPDATE `id`=VALUES(`id`),`name`=VALUES(`name`)", Args: []interface{}{3, "x"}},
+		},
+		{
+			name:    "merge",
+			dialect: SQLServer,
+			s:       filtered,
+			replace: true,
+			want:    Statement{SQL: "MERGE INTO [t] AS t USING (SELECT [id], lower(n) AS [name] FROM [s] WHERE id > @p1 AND n <> @p2) AS s ([id], [name]) ON (t.[id] = s.[id]) WHEN MATCHED THEN UPDATE SET t.[name] = s.[name] WHEN NOT MATCHED THEN INSERT ([id], [name]) VALUES (s.[id], s.[name]);", Args: []interface{}{3, "x"}},
+		},
+		{
+			name:    "invalid source",
+			dialect: Postgres,
+			s:       &Select{From: "s;"},
+			wantErr: `Invalid identifier "s;"`,
+		},
+		{
+			name:    "column which isn't inserted",
+			dialect: Postgres,
+			s:       &Select{From: "s", Columns: map[string]string{"z": "1"}},
+			wantErr: "The column z of the select isn't inserted",
+		},
+		{
+			name:    "missing argument",
+			dialect: Postgres,
+			s:       &Select{From: "s", Where: "id > ?"},
+			wantErr: "has 1 placeholders, but 0 arguments are given",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := New(tt.dialect)
+			b.Init("t", "id", "name")
+			b.SetConflictTarget("id")
+			b.SetInterpolate(tt.interpolate)
+			got, err := b.InsertSelectStatement(tt.s, tt.replace)
+			if !checkError(t, err, tt.wantErr) {
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %q, expected %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestInsertSelect(t *testing.T) {
+	r := NewRecorder()
+	b := New(Postgres)
+	b.Init("t", "id", "name")
+	b.PrepareValues(1, "a")
+	if _, err := b.InsertSelect(context.Background(), r.DB(), &Select{From: "s", Where: "id > ?", Args: []interface{}{3}}, false); err != nil {
+		t.Fatal(err)
+	}
+	// The buffered rows aren't inserted
+	err := r.Expect(Statement{SQL: `INSERT INTO "t"("id", "name") SELECT "id", "name" FROM "s" WHERE id > $1`, Args: []interface{}{3}})
+	if err != nil {
+		t.Error(err)
+	}
+}
+
+func TestInsertSelectNotInitialized(t *testing.T) {
+	b := New(Postgres)
+	if _, err := b.InsertSelectStatement(&Select{From: "s"}, false); err != ErrNotInitialized {
+		t.Errorf("got the error %v, expected %v", err, ErrNotInitialized)
+	}
+}
@@ -0,0 +1,228 @@
+package bulk
+
+import (
+	"database/sql/driver"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Interpolator is implemented by the dialects which can write the values as SQL literals, which
+// SetInterpolate requires. All the provided dialects implement it.
+type Interpolator interface {
+	// Literal returns v, a driver value (nil, int64, float64, bool, []byte, string or time.Time), written
+	// as a SQL literal.
+	Literal(v driver.Value) (string, error)
+}
+
+// SetInterpolate makes Insert and Exec write the values into the statements as escaped literals and
+// execute them without preparing them, instead of sending them as bind parameters. It can be faster with
+// drivers which prepare every statement in a round trip, and it avoids the limits on prepared statements
+// of some servers and proxies. The values are converted as database/sql does, so driver.Valuer types are
+// supported. The escaping of MySQL assumes the default SQL mode: with NO_BACKSLASH_ESCAPES the backslashes
+// would be doubled. The dialect must implement Interpolator.
+func (b *Bulk) SetInterpolate(interpolate bool) {
+	b.interpolate = interpolate
+}
+
+// batchStatement returns the statement inserting the rows of bt and its arguments, which are written
+// into the statement if the values are interpolated.
+func (b *Bulk) batchStatement(head string, bt batch, clause string) (string, []interface{}, error) {
+	if !b.interpolate {
+		return b.statement(head, bt, clause), b.args(bt), nil
+	}
+	in, ok := b.dialect.(Interpolator)
+	if !ok {
+		return "", nil, fmt.Errorf("ERROR: The dialect doesn't support interpolating the values")
+	}
+	source := b.rowSource()
+	if source != "" {
+		head = strings.TrimSuffix(head, "VALUES ")
+	}
+	var sb strings.Builder
+	sb.Grow(len(head) + bt.rows()*b.valuesPerRow*8 + len(clause))
+	sb.WriteString(head)
+	exprs := b.exprParts()
+	err := b.writeRows(&sb, bt, source, func(row, col int) error {
+		v := b.vals[row*b.valuesPerRow+col]
+		if raw, ok := v.(Raw); ok {
+			sb.WriteString(string(raw))
+			return nil
+		}
+		args, parts := []interface{}{v}, []string{"", ""}
+		if exprs != nil && exprs[col] != nil {
+			args, parts = exprArgs(exprs[col], v), exprs[col]
+		}
+		sb.WriteString(parts[0])
+		for i, a := range args {
+			dv, err := driver.DefaultParameterConverter.ConvertValue(a)
+			if err != nil {
+				return fmt.Errorf("ERROR: The value of the column %v in the row %v can't be interpolated: %w", b.columns[col], row, err)
+			}
+			lit, err := in.Literal(dv)
+			if err != nil {
+				return fmt.Errorf("ERROR: The value of the column %v in the row %v can't be interpolated: %w", b.columns[col], row, err)
+			}
+			sb.WriteString(lit)
+			sb.WriteString(parts[i+1])
+		}
+		return nil
+	})
+	if err != nil {
+		return "", nil, err
+	}
+	sb.WriteString(clause)
+	return sb.String(), nil, nil
+}
+
+// interpolateExpr writes to sb the expression split in parts at its placeholders, with the literals of
+// args in their place.
+func (b *Bulk) interpolateExpr(sb *strings.Builder, parts []string, args []interface{}) error {
+	in, ok := b.dialect.(Interpolator)
+	if !ok {
+		return fmt.Errorf("ERROR: The dialect doesn't support interpolating the values")
+	}
+	sb.WriteString(parts[0])
+	for i, a := range args {
+		dv, err := driver.DefaultParameterConverter.ConvertValue(a)
+		if err != nil {
+			return fmt.Errorf("ERROR: The argument %v can't be interpolated: %w", i, err)
+		}
+		lit, err := in.Literal(dv)
+		if err != nil {
+			return fmt.Errorf("ERROR: The argument %v can't be interpolated: %w", i, err)
+		}
+		sb.WriteString(lit)
+		sb.WriteString(parts[i+1])
+	}
+	return nil
+}
+
+// literalFormat describes how a dialect writes each type of literal.
+type literalFormat struct {
+	quote      func(s string) (string, error) // Writes a string literal
+	bytes      func(b []byte) string          // Writes a binary literal
+	timeLayout string                         // Layout of the time literals, which are quoted as strings
+	timePrefix string                         // Written before the time literals, e.g. "TIMESTAMP "
+	trueLit    string                         // Literal of true
+	falseLit   string                         // Literal of false
+}
+
+// literal writes v, a driver value, as f describes.
+func (f *literalFormat) literal(v driver.Value) (string, error) {
+	switch v := v.(type) {
+	case nil:
+		return "NULL", nil
+	case int64:
+		return strconv.FormatInt(v, 10), nil
+	case float64:
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			return "", fmt.Errorf("%v can't be written as a literal", v)
+		}
+		return strconv.FormatFloat(v, 'g', -1, 64), nil
+	case bool:
+		if v {
+			return f.trueLit, nil
+		}
+		return f.falseLit, nil
+	case []byte:
+		return f.bytes(v), nil
+	case string:
+		return f.quote(v)
+	case time.Time:
+		s, err := f.quote(v.Format(f.timeLayout))
+		return f.timePrefix + s, err
+	}
+	return "", fmt.Errorf("%T isn't a driver value", v)
+}
+
+// quoteStandard writes s as a standard SQL string literal, doubling the quotes. The NUL characters, which
+// can't be written in such a literal, are an error.
+func quoteStandard(s string) (string, error) {
+	if strings.IndexByte(s, 0) >= 0 {
+		return "", fmt.Errorf("the string contains a NUL character")
+	}
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'", nil
+}
+
+var (
+	mysqlLiterals = &literalFormat{
+		quote: func(s string) (string, error) {
+			r := strings.NewReplacer(`\`, `\\`, "'", "''", "\x00", `\0`)
+			return "'" + r.Replace(s) + "'", nil
+		},
+		bytes:      func(b []byte) string { return "X'" + hex.EncodeToString(b) + "'" },
+		timeLayout: "2006-01-02 15:04:05.999999",
+		trueLit:    "1",
+		falseLit:   "0",
+	}
+	postgresLiterals = &literalFormat{
+		quote:      quoteStandard,
+		bytes:      func(b []byte) string { return `'\x` + hex.EncodeToString(b) + "'" },
+		timeLayout: "2006-01-02 15:04:05.999999Z07:00",
+		trueLit:    "TRUE",
+		falseLit:   "FALSE",
+	}
+	sqlServerLiterals = &literalFormat{
+		quote: func(s string) (string, error) {
+			s, err := quoteStandard(s)
+			return "N" + s, err
+		},
+		bytes:      func(b []byte) string { return "0x" + hex.EncodeToString(b) },
+		timeLayout: "2006-01-02T15:04:05.9999999",
+		trueLit:    "1",
+		falseLit:   "0",
+	}
+	oracleLiterals = &literalFormat{
+		quote:      quoteStandard,
+		bytes:      func(b []byte) string { return "HEXTORAW('" + hex.EncodeToString(b) + "')" },
+		timeLayout: "2006-01-02 15:04:05.999999999",
+		timePrefix: "TIMESTAMP ",
+		trueLit:    "1",
+		falseLit:   "0",
+	}
+	sqliteLiterals = &literalFormat{
+		quote:      quoteStandard,
+		bytes:      func(b []byte) string { return "X'" + hex.EncodeToString(b) + "'" },
+		timeLayout: "2006-01-02 15:04:05.999999999-07:00",
+		trueLit:    "1",
+		falseLit:   "0",
+	}
+	clickHouseLiterals = &literalFormat{
+		quote: func(s string) (string, error) {
+			r := strings.NewReplacer(`\`, `\\`, "'", `\'`, "\x00", `\0`)
+			return "'" + r.Replace(s) + "'", nil
+		},
+		bytes:      func(b []byte) string { return "unhex('" + hex.EncodeToString(b) + "')" },
+		timeLayout: "2006-01-02 15:04:05.999999999",
+		trueLit:    "1",
+		falseLit:   "0",
+	}
+)
+
+func (mysqlDialect) Literal(v driver.Value) (string, error) {
+	return mysqlLiterals.literal(v)
+}
+
+func (postgresDialect) Literal(v driver.Value) (string, error) {
+	return postgresLiterals.literal(v)
+}
+
+func (sqlServerDialect) Literal(v driver.Value) (string, error) {
+	return sqlServerLiterals.literal(v)
+}
+
+func (oracleDialect) Literal(v driver.Value) (string, error) {
+	return oracleLiterals.literal(v)
+}
+
+func (clickHouseDialect) Literal(v driver.Value) (string, error) {
+	return clickHouseLiterals.literal(v)
+}
+
+func (sqliteDialect) Literal(v driver.Value) (string, error) {
+	return sqliteLiterals.literal(v)
+}
@@ -0,0 +1,80 @@
+package bulk
+
+import (
+	"context"
+	"math"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestInterpolate(t *testing.T) {
+	at := time.Date(2020, 1, 2, 3, 4, 5, 600000000, time.UTC)
+	row := []interface{}{int64(1), 1.5, `it's \`, []byte{1, 255}, true, at, nil}
+	tests := []struct {
+		name    string
+		dialect Dialect
+		want    string
+	}{
+		{name: "mysql", dialect: MySQL, want: "INSERT INTO `t`(`a`, `b`, `c`, `d`, `e`, `f`, `g`) VALUES (1,1.5,'it''s \\\\',X'01ff',1,'2020-01-02 03:04:05.6',NULL)"},
+		{name: "postgres", dialect: Postgres, want: `INSERT INTO "t"("a", "b", "c", "d", "e", "f", "g") VALUES (1,1.5,'it''s \','\x01ff',TRUE,'2020-01-02 03:04:05.6Z',NULL)`},
+		{name: "sql server", dialect: SQLServer, want: `INSERT INTO [t]([a], [b], [c], [d], [e], [f], [g]) VALUES (1,1.5,N'it''s \',0x01ff,1,N'2020-01-02T03:04:05.6',NULL)`},
+		{name: "oracle", dialect: Oracle, want: `INSERT INTO "t"("a", "b", "c", "d", "e", "f", "g") SELECT 1 "a",1.5 "b",'it''s \' "c",HEXTORAW('01ff') "d",1 "e",TIMESTAMP '2020-01-02 03:04:05.6' "f",NULL "g" FROM dual`},
+		{name: "sqlite", dialect: SQLite, want: `INSERT INTO "t"("a", "b", "c", "d", "e", "f", "g") VALUES (1,1.5,'it''s \',X'01ff',1,'2020-01-02 03:04:05.6+00:00',NULL)`},
+		{name: "clickhouse", dialect: ClickHouse, want: "INSERT INTO `t`(`a`, `b`, `c`, `d`, `e`, `f`, `g`) VALUES (1,1.5,'it\\'s \\\\',unhex('01ff'),1,'2020-01-02 03:04:05.6',NULL)"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := New(tt.dialect)
+			b.Init("t", "a", "b", "c", "d", "e", "f", "g")
+			b.SetInterpolate(true)
+			b.PrepareValues(row...)
+			statements, err := b.BuildStatements(false)
+			if err != nil {
+				t.Fatal(err)
+			}
+			want := []Statement{{SQL: tt.want}}
+			if !reflect.DeepEqual(statements, want) {
+				t.Errorf("got %q, expected %q", statements, want)
+			}
+		})
+	}
+}
+
+func TestInterpolateErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		dialect Dialect
+		value   interface{}
+		wantErr string
+	}{
+		{name: "nan", dialect: MySQL, value: math.NaN(), wantErr: "The value of the column a in the row 0 can't be interpolated: NaN can't be written as a literal"},
+		{name: "nul", dialect: Postgres, value: "a\x00", wantErr: "the string contains a NUL character"},
+		{name: "type", dialect: MySQL, value: struct{}{}, wantErr: "can't be interpolated: unsupported type struct {}"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := New(tt.dialect)
+			b.Init("t", "a")
+			b.SetInterpolate(true)
+			b.PrepareValues(tt.value)
+			_, err := b.BuildStatements(false)
+			checkError(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestInterpolateExec(t *testing.T) {
+	r := NewRecorder()
+	b := New(MySQL)
+	b.Init("t", "id")
+	b.SetInterpolate(true)
+	b.PrepareValues(1)
+	// The statement is executed without preparing it nor arguments
+	if err := b.InsertContext(context.Background(), r.DB(), false); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Expect(Statement{SQL: "INSERT INTO `t`(`id`) VALUES (1)"}); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,38 @@
+package bulk
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// SetJSONColumns marks columns as JSON, for MySQL JSON or Postgres jsonb columns: their values are
+// marshaled with EncodeJSON, replacing any encoder set for them.
+func (b *Bulk) SetJSONColumns(columns ...string) {
+	for _, c := range columns {
+		b.SetEncoder(c, EncodeJSON)
+	}
+}
+
+// EncodeJSON is an Encoder converting any value to its JSON text with encoding/json. The strings, byte
+// slices and json.RawMessage values are taken as JSON text already, and only validated.
+func EncodeJSON(v interface{}) (interface{}, error) {
+	var text []byte
+	switch v := v.(type) {
+	case string:
+		text = []byte(v)
+	case []byte:
+		text = v
+	case json.RawMessage:
+		text = v
+	default:
+		data, err := json.Marshal(v)
+		if err != nil {
+			return nil, err
+		}
+		return string(data), nil
+	}
+	if !json.Valid(text) {
+		return nil, fmt.Errorf("invalid JSON text")
+	}
+	return string(text), nil
+}
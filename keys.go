@@ -0,0 +1,54 @@
+package bulk
+
+import "fmt"
+
+// KeyDisabler is implemented by the dialects which can stop updating the nonunique indexes of a table
+// while loading it, rebuilding them at the end, such as MySQL for MyISAM and Aria tables.
+type KeyDisabler interface {
+	// DisableKeys returns the statement disabling the nonunique indexes of table, already quoted.
+	DisableKeys(table string) string
+	// EnableKeys returns the statement enabling the indexes of table again, already quoted.
+	EnableKeys(table string) string
+}
+
+func (mysqlDialect) DisableKeys(table string) string {
+	return "ALTER TABLE " + table + " DISABLE KEYS"
+}
+
+func (mysqlDialect) EnableKeys(table string) string {
+	return "ALTER TABLE " + table + " ENABLE KEYS"
+}
+
+// SetDisableKeys makes Exec, and the methods built on it, disable the nonunique indexes of the table
+// before inserting the rows and enable them afterwards, even if the load fails, so they are rebuilt once
+// instead of being updated row by row. The dialect must implement KeyDisabler. As the statements are DDL,
+// db shouldn't be a *sql.Tx.
+func (b *Bulk) SetDisableKeys(disable bool) {
+	b.disableKeys = disable
+}
+
+// SetIndexDDL sets statements dropping indexes of the table, run by Exec, and the methods built on it,
+// before inserting the rows, and the ones creating them again, run afterwards even if the load fails. It
+// works with any database, while SetDisableKeys is limited to some engines. As the statements are DDL,
+// db shouldn't be a *sql.Tx.
+func (b *Bulk) SetIndexDDL(drop, create []string) {
+	b.dropIndexes = drop
+	b.createIndexes = create
+}
+
+// aroundStatements returns the statements run before the load and the ones run after it: the ones
+// set with SetSession, SetDisableKeys and SetIndexDDL. The session is set first and restored last.
+func (b *Bulk) aroundStatements() (setup, teardown []string, err error) {
+	setup = append(setup, b.sessionSetup...)
+	if b.disableKeys {
+		k, ok := b.dialect.(KeyDisabler)
+		if !ok {
+			return nil, nil, fmt.Errorf("ERROR: The dialect %T can't disable the keys of a table", b.dialect)
+		}
+		setup = append(setup, k.DisableKeys(b.quote(b.table)))
+		teardown = append(teardown, k.EnableKeys(b.quote(b.table)))
+	}
+	setup = append(setup, b.dropIndexes...)
+	teardown = append(append(append([]string(nil), b.createIndexes...), teardown...), b.sessionTeardown...)
+	return setup, teardown, nil
+}
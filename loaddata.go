@@ -0,0 +1,147 @@
+package bulk
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// LoadDataOptions configures the LoadData backend. A nil *LoadDataOptions uses the defaults.
+type LoadDataOptions struct {
+	// RegisterReader streams the rows to the driver without writing a file, e.g. mysql.RegisterReaderHandler
+	// of github.com/go-sql-driver/mysql, and DeregisterReader removes the reader once loaded, e.g.
+	// mysql.DeregisterReaderHandler.
+	RegisterReader   func(name string, handler func() io.Reader)
+	DeregisterReader func(name string)
+	// If RegisterReader isn't set, the rows are written to a temporary file in Dir, os.TempDir() by default.
+	// The driver must allow reading it, e.g. registering it with mysql.RegisterLocalFile in RegisterFile and
+	// mysql.DeregisterLocalFile in DeregisterFile, or with allowAllFiles=true in the DSN.
+	Dir            string
+	RegisterFile   func(path string)
+	DeregisterFile func(path string)
+}
+
+// readerCount numbers the readers registered by LoadData.
+var readerCount int64
+
+// LoadData returns a MySQL Backend which loads the rows with LOAD DATA LOCAL INFILE, much faster than
+// insert statements for large loads. The server must have local_infile enabled. The duplicates are
+// replaced with SetReplaceInto and ignored with SetIgnoreDuplicates, while updating them isn't supported.
+// Note that MySQL ignores the rows colliding with an existing key by default when loading local files.
+func LoadData(opts *LoadDataOptions) Backend {
+	if opts == nil {
+		opts = &LoadDataOptions{}
+	}
+	return &loadData{opts: opts}
+}
+
+// loadData is the Backend returned by LoadData.
+type loadData struct {
+	opts *LoadDataOptions
+}
+
+// Load writes the rows of t in the LOAD DATA format and loads them into the table.
+func (l *loadData) Load(ctx context.Context, db Execer, t *Table) (sql.Result, error) {
+	handling := ""
+	if t.Conflict != nil {
+		switch t.Conflict.Action {
+		case DoReplace:
+			handling = " REPLACE"
+		case DoNothing:
+			handling = " IGNORE"
+		default:
+			return nil, fmt.Errorf("ERROR: LOAD DATA can't update the duplicates, use SetReplaceInto or SetIgnoreDuplicates")
+		}
+	}
+	var buf bytes.Buffer
+	if err := writeLoadData(&buf, t.Rows); err != nil {
+		return nil, err
+	}
+
+	var file string
+	if l.opts.RegisterReader != nil {
+		name := "bulk_" + strconv.FormatInt(atomic.AddInt64(&readerCount, 1), 10)
+		data := buf.Bytes()
+		l.opts.RegisterReader(name, func() io.Reader { return bytes.NewReader(data) })
+		if l.opts.DeregisterReader != nil {
+			defer l.opts.DeregisterReader(name)
+		}
+		file = "Reader::" + name
+	} else {
+		f, err := os.CreateTemp(l.opts.Dir, "bulk-*.tsv")
+		if err != nil {
+			return nil, err
+		}
+		defer os.Remove(f.Name())
+		_, err = f.Write(buf.Bytes())
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+		if err != nil {
+			return nil, err
+		}
+		if l.opts.RegisterFile != nil {
+			l.opts.RegisterFile(f.Name())
+			if l.opts.DeregisterFile != nil {
+				defer l.opts.DeregisterFile(f.Name())
+			}
+		}
+		file = f.Name()
+	}
+
+	str := "LOAD DATA LOCAL INFILE '" + strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(file) + "'" + handling +
+		" INTO TABLE " + t.QuotedName + " CHARACTER SET utf8mb4" +
+		` FIELDS TERMINATED BY '\t' ESCAPED BY '\\' LINES TERMINATED BY '\n'` +
+		" (" + strings.Join(t.QuotedColumns, ", ") + ")"
+	return db.ExecContext(ctx, str)
+}
+
+// loadDataEscaper escapes the characters with a special meaning in the LOAD DATA format.
+var loadDataEscaper = strings.NewReplacer(`\`, `\\`, "\t", `\t`, "\n", `\n`, "\r", `\r`, "\x00", `\0`)
+
+// writeLoadData writes rows to w as tab separated lines, with NULL written as \N.
+func writeLoadData(w *bytes.Buffer, rows [][]interface{}) error {
+	for _, row := range rows {
+		for i, v := range row {
+			if i > 0 {
+				w.WriteByte('\t')
+			}
+			v, err := driver.DefaultParameterConverter.ConvertValue(v)
+			if err != nil {
+				return err
+			}
+			switch v := v.(type) {
+			case nil:
+				w.WriteString(`\N`)
+			case int64:
+				w.WriteString(strconv.FormatInt(v, 10))
+			case float64:
+				w.WriteString(strconv.FormatFloat(v, 'g', -1, 64))
+			case bool:
+				if v {
+					w.WriteByte('1')
+				} else {
+					w.WriteByte('0')
+				}
+			case []byte:
+				loadDataEscaper.WriteString(w, string(v))
+			case string:
+				loadDataEscaper.WriteString(w, v)
+			case time.Time:
+				w.WriteString(v.Format("2006-01-02 15:04:05.999999"))
+			default:
+				return fmt.Errorf("ERROR: LOAD DATA can't write the value %v of type %T", v, v)
+			}
+		}
+		w.WriteByte('\n')
+	}
+	return nil
+}
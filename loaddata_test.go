@@ -0,0 +1,100 @@
+package bulk
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLoadData(t *testing.T) {
+	tests := []struct {
+		name     string
+		setup    func(b *Bulk)
+		replace  bool
+		handling string
+		wantErr  string
+	}{
+		{name: "insert"},
+		{name: "replace", setup: func(b *Bulk) { b.SetReplaceInto(true) }, handling: " REPLACE"},
+		{name: "ignore", setup: func(b *Bulk) { b.SetIgnoreDuplicates(true) }, handling: " IGNORE"},
+		{name: "update", replace: true, wantErr: "LOAD DATA can't update the duplicates"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var name, data string
+			deregistered := false
+			opts := &LoadDataOptions{
+				RegisterReader: func(n string, handler func() io.Reader) {
+					name = n
+					b, _ := io.ReadAll(handler())
+					data = string(b)
+				},
+				DeregisterReader: func(n string) { deregistered = n == name },
+			}
+			r := NewRecorder()
+			b := New(MySQL)
+			b.Init("t", "id", "name", "at")
+			b.SetBackend(LoadData(opts))
+			if tt.setup != nil {
+				tt.setup(b)
+			}
+			b.PrepareValues(1, "a\tb\\c\nd", time.Date(2020, 1, 2, 3, 4, 5, 600000000, time.UTC))
+			b.PrepareValues(2.5, nil, true)
+			_, err := b.Exec(context.Background(), r.DB(), tt.replace)
+			if !checkError(t, err, tt.wantErr) {
+				return
+			}
+			want := "LOAD DATA LOCAL INFILE 'Reader::" + name + "'" + tt.handling + " INTO TABLE `t` CHARACTER SET utf8mb4" +
+				` FIELDS TERMINATED BY '\t' ESCAPED BY '\\' LINES TERMINATED BY '\n'` + " (`id`, `name`, `at`)"
+			if err := r.ExpectSQL(want); err != nil {
+				t.Error(err)
+			}
+			if want := "1\ta\\tb\\\\c\\nd\t2020-01-02 03:04:05.6\n2.5\t\\N\t1\n"; data != want {
+				t.Errorf("got the data %q, expected %q", data, want)
+			}
+			if !deregistered {
+				t.Error("the reader isn't deregistered")
+			}
+		})
+	}
+}
+
+func TestLoadDataFile(t *testing.T) {
+	var path, data string
+	opts := &LoadDataOptions{
+		Dir: t.TempDir(),
+		RegisterFile: func(p string) {
+			path = p
+			b, _ := os.ReadFile(p)
+			data = string(b)
+		},
+	}
+	r := NewRecorder()
+	b := New(MySQL)
+	b.Init("t", "id")
+	b.SetBackend(LoadData(opts))
+	b.PrepareValues(1)
+	if err := b.Insert(r.DB(), false); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(path, opts.Dir) || data != "1\n" {
+		t.Errorf("got the file %v with %q", path, data)
+	}
+	if statements := r.Statements(); len(statements) != 1 || !strings.HasPrefix(statements[0].SQL, "LOAD DATA LOCAL INFILE '"+path+"' INTO TABLE `t`") {
+		t.Errorf("got %v", statements)
+	}
+	// The file is removed once loaded
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("got %v, expected the file to be removed", err)
+	}
+}
+
+func TestWriteLoadDataType(t *testing.T) {
+	var buf bytes.Buffer
+	err := writeLoadData(&buf, [][]interface{}{{struct{}{}}})
+	checkError(t, err, "unsupported type struct {}")
+}
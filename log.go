@@ -0,0 +1,39 @@
+package bulk
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// maxLoggedSQL is the number of characters of a statement which are logged.
+const maxLoggedSQL = 256
+
+// SetLogger makes the Bulk log every executed batch with l: the statement (truncated), the number of
+// parameters, the batch number and the execution time. Successful batches are logged at debug level and
+// failed ones at error level. A nil logger disables the logging, which is the default.
+func (b *Bulk) SetLogger(l *slog.Logger) {
+	b.logger = l
+}
+
+// logBatch logs the execution of the batch bt with the statement str.
+func (b *Bulk) logBatch(ctx context.Context, bt batch, str string, elapsed time.Duration, err error) {
+	if b.logger == nil {
+		return
+	}
+	if len(str) > maxLoggedSQL {
+		str = str[:maxLoggedSQL] + "..."
+	}
+	attrs := []slog.Attr{
+		slog.Int("batch", bt.index),
+		slog.Int("rows", bt.rows()),
+		slog.Int("params", bt.rows()*b.valuesPerRow),
+		slog.Duration("elapsed", elapsed),
+		slog.String("sql", str),
+	}
+	if err != nil {
+		b.logger.LogAttrs(ctx, slog.LevelError, "bulk: batch failed", append(attrs, slog.Any("error", err))...)
+		return
+	}
+	b.logger.LogAttrs(ctx, slog.LevelDebug, "bulk: batch executed", attrs...)
+}
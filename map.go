@@ -0,0 +1,48 @@
+package bulk
+
+import (
+	"fmt"
+	"sort"
+)
+
+// SetMapDefaults sets the values used by AddMap for the columns missing from a map. A column set to nil
+// is inserted as NULL when missing.
+func (b *Bulk) SetMapDefaults(defaults map[string]interface{}) {
+	b.mapDefaults = defaults
+}
+
+// SetMissingAsNull makes AddMap insert NULL in the columns missing from a map which have no default,
+// instead of failing.
+func (b *Bulk) SetMissingAsNull(null bool) {
+	b.missingAsNull = null
+}
+
+// AddMap appends a row with the values of m, whose keys are the column names given to Init. A key which
+// isn't a column is an error, as well as a missing column, unless it has a default set with
+// SetMapDefaults or SetMissingAsNull is enabled.
+func (b *Bulk) AddMap(m map[string]interface{}) error {
+	columns := b.inputColumns()
+	vals := make([]interface{}, len(columns))
+	found := 0
+	for i, c := range columns {
+		if v, ok := m[c]; ok {
+			vals[i] = v
+			found++
+		} else if v, ok := b.mapDefaults[c]; ok {
+			vals[i] = v
+		} else if !b.missingAsNull {
+			return fmt.Errorf("ERROR: The map has no value for the column %v", c)
+		}
+	}
+	if found < len(m) {
+		var unknown []string
+		for k := range m {
+			if !contains(columns, k) {
+				unknown = append(unknown, k)
+			}
+		}
+		sort.Strings(unknown)
+		return fmt.Errorf("ERROR: The map has keys which aren't columns: %v", unknown)
+	}
+	return b.PrepareValues(vals...)
+}
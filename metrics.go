@@ -0,0 +1,144 @@
+package bulk
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Metrics receives the outcome of every executed batch, e.g. to alert when the ingestion throughput
+// drops. PrometheusMetrics exposes them to Prometheus without depending on its client library, which
+// can also be used by implementing this interface with its counters and histograms.
+type Metrics interface {
+	// ObserveBatch is called after executing a batch of rows into table, including the retries, which
+	// took d and failed with err if it isn't nil. With SetParallelism, it's called from several
+	// goroutines at the same time.
+	ObserveBatch(table string, rows int, d time.Duration, err error)
+}
+
+// SetMetrics makes the Bulk report every executed batch to m. A nil m disables the metrics, which is the
+// default.
+func (b *Bulk) SetMetrics(m Metrics) {
+	b.metrics = m
+}
+
+// observeBatch reports a batch to the metrics, if any.
+func (b *Bulk) observeBatch(rows int, d time.Duration, err error) {
+	if b.metrics != nil {
+		b.metrics.ObserveBatch(b.table, rows, d, err)
+	}
+}
+
+// DefaultBuckets are the upper bounds, in seconds, of the batch duration histogram of PrometheusMetrics
+// by default, the same ones as the Prometheus client.
+var DefaultBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+// PrometheusMetrics is a Metrics which serves, in the Prometheus text format, the counters of inserted
+// rows, executed batches and failed batches, and the histogram of the batch durations, by table:
+//
+//	<namespace>_rows_inserted_total{table="..."}
+//	<namespace>_batches_total{table="..."}
+//	<namespace>_batch_failures_total{table="..."}
+//	<namespace>_batch_duration_seconds{table="..."}
+//
+// It's an http.Handler, usually registered at /metrics. It's safe for concurrent use.
+type PrometheusMetrics struct {
+	namespace string
+	buckets   []float64
+	mu        sync.Mutex
+	tables    map[string]*tableMetrics
+}
+
+// tableMetrics holds the metrics of a table.
+type tableMetrics struct {
+	rows, batches, failures int64
+	counts                  []int64 // Number of batches in each bucket, not cumulative
+	sum                     float64 // Sum of the durations in seconds
+}
+
+// NewPrometheusMetrics returns a PrometheusMetrics whose metric names start with namespace, e.g. "bulk",
+// and whose duration histogram has the upper bounds buckets, in seconds and increasing. A nil buckets
+// uses DefaultBuckets.
+func NewPrometheusMetrics(namespace string, buckets []float64) *PrometheusMetrics {
+	if buckets == nil {
+		buckets = DefaultBuckets
+	}
+	return &PrometheusMetrics{namespace: namespace, buckets: buckets, tables: map[string]*tableMetrics{}}
+}
+
+// ObserveBatch records a batch.
+func (p *PrometheusMetrics) ObserveBatch(table string, rows int, d time.Duration, err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	t, ok := p.tables[table]
+	if !ok {
+		t = &tableMetrics{counts: make([]int64, len(p.buckets)+1)}
+		p.tables[table] = t
+	}
+	t.batches++
+	if err != nil {
+		t.failures++
+	} else {
+		t.rows += int64(rows)
+	}
+	seconds := d.Seconds()
+	i := sort.SearchFloat64s(p.buckets, seconds)
+	t.counts[i]++
+	t.sum += seconds
+}
+
+// ServeHTTP writes the metrics in the Prometheus text format.
+func (p *PrometheusMetrics) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	p.WriteTo(w)
+}
+
+// WriteTo writes the metrics in the Prometheus text format to w.
+func (p *PrometheusMetrics) WriteTo(w io.Writer) (int64, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	tables := make([]string, 0, len(p.tables))
+	for t := range p.tables {
+		tables = append(tables, t)
+	}
+	sort.Strings(tables)
+
+	var sb strings.Builder
+	counter := func(name, help string, value func(*tableMetrics) int64) {
+		name = p.namespace + "_" + name
+		fmt.Fprintf(&sb, "# HELP %s %s\n# TYPE %s counter\n", name, help, name)
+		for _, t := range tables {
+			fmt.Fprintf(&sb, "%s{table=%s} %d\n", name, labelValue(t), value(p.tables[t]))
+		}
+	}
+	counter("rows_inserted_total", "Rows inserted by the successful batches.", func(t *tableMetrics) int64 { return t.rows })
+	counter("batches_total", "Executed batches.", func(t *tableMetrics) int64 { return t.batches })
+	counter("batch_failures_total", "Failed batches.", func(t *tableMetrics) int64 { return t.failures })
+
+	name := p.namespace + "_batch_duration_seconds"
+	fmt.Fprintf(&sb, "# HELP %s Duration of the batches, including the retries.\n# TYPE %s histogram\n", name, name)
+	for _, table := range tables {
+		t := p.tables[table]
+		label := labelValue(table)
+		var cumulative int64
+		for i, le := range p.buckets {
+			cumulative += t.counts[i]
+			fmt.Fprintf(&sb, "%s_bucket{table=%s,le=\"%s\"} %d\n", name, label, strconv.FormatFloat(le, 'g', -1, 64), cumulative)
+		}
+		fmt.Fprintf(&sb, "%s_bucket{table=%s,le=\"+Inf\"} %d\n", name, label, t.batches)
+		fmt.Fprintf(&sb, "%s_sum{table=%s} %s\n", name, label, strconv.FormatFloat(t.sum, 'g', -1, 64))
+		fmt.Fprintf(&sb, "%s_count{table=%s} %d\n", name, label, t.batches)
+	}
+	n, err := io.WriteString(w, sb.String())
+	return int64(n), err
+}
+
+// labelValue returns s quoted as a Prometheus label value.
+func labelValue(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(s) + `"`
+}
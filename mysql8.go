@@ -0,0 +1,99 @@
+package bulk
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// MySQL8 is the MySQL dialect for MySQL 8.0.19 and later, whose upserts name the inserted row with an
+// alias instead of using the VALUES() function, deprecated since MySQL 8.0.20:
+//
+//	INSERT INTO `t`(`id`, `n`) VALUES (?,?) AS `new` ON DUPLICATE KEY UPDATE `n`=`new`.`n`
+//
+// The expressions of OnDuplicateUpdateExpr can reference the inserted row as `new`, e.g. "counter": "counter
+// + new.counter". MySQLVersion and DetectMySQL select the dialect for a server version.
+var MySQL8 Dialect = mysql8Dialect{}
+
+// mysqlRowAlias is the alias of the inserted row in the upserts of MySQL8.
+const mysqlRowAlias = "`new`"
+
+// mysql8Dialect is MySQL with every optional feature of it, only its upserts differ.
+type mysql8Dialect struct {
+	mysqlDialect
+}
+
+func (mysql8Dialect) Upsert(c *Conflict) (string, string, error) {
+	if c.Action != DoUpdate {
+		return mysqlDialect{}.Upsert(c)
+	}
+	if c.TargetWhere != "" || c.UpdateWhere != "" {
+		return "", "", fmt.Errorf("ERROR: MySQL doesn't support conditions in ON DUPLICATE KEY UPDATE")
+	}
+	if len(c.Update) == 0 && len(c.Set) == 0 {
+		return "", "", errNoUpdate
+	}
+	endStr := " AS " + mysqlRowAlias + " ON DUPLICATE KEY UPDATE "
+	for _, v := range c.Update {
+		endStr += v + "=" + mysqlRowAlias + "." + v + ","
+	}
+	for _, v := range c.setColumns() {
+		endStr += v + "=" + c.Set[v] + ","
+	}
+	return "INSERT INTO", endStr[:len(endStr)-1], nil
+}
+
+// MySQLVersion returns the dialect for the server whose version is version, as returned by SELECT
+// VERSION(), e.g. "8.0.36" or "10.11.6-MariaDB": MySQL8 for MySQL 8.0.19 and later, and MySQL for the
+// older versions and MariaDB, which doesn't support the row alias.
+func MySQLVersion(version string) Dialect {
+	if strings.Contains(strings.ToLower(version), "mariadb") {
+		return MySQL
+	}
+	if i := strings.IndexAny(version, "-+ "); i >= 0 {
+		version = version[:i]
+	}
+	var v [3]int
+	for i, p := range strings.SplitN(version, ".", 3) {
+		n, err := strconv.Atoi(p)
+		if err != nil {
+			return MySQL
+		}
+		v[i] = n
+	}
+	if v[0] > 8 || v[0] == 8 && (v[1] > 0 || v[2] >= 19) {
+		return MySQL8
+	}
+	return MySQL
+}
+
+// DetectMySQL returns the dialect for the MySQL server of db, see MySQLVersion.
+func DetectMySQL(ctx context.Context, db Querier) (Dialect, error) {
+	rows, err := db.QueryContext(ctx, "SELECT VERSION()")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("ERROR: The server didn't return its version")
+	}
+	var version string
+	if err := rows.Scan(&version); err != nil {
+		return nil, err
+	}
+	return MySQLVersion(version), rows.Err()
+}
+
+// selectRows returns the part of an INSERT ... SELECT statement following its column list, given its
+// select and its upsert clause. The row alias of MySQL8 can't follow a select, so the select is made a
+// derived table with the alias instead.
+func selectRows(sel, clause string) string {
+	if rest, ok := strings.CutPrefix(clause, " AS "+mysqlRowAlias+" "); ok {
+		return "SELECT * FROM (" + sel + ") AS " + mysqlRowAlias + " " + rest
+	}
+	return sel + clause
+}
@@ -0,0 +1,134 @@
+package bulk
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+)
+
+// NDJSONOptions configures FromNDJSON. A nil *NDJSONOptions uses the defaults.
+type NDJSONOptions struct {
+	// Keys maps an object key to its column. By default the keys must match the column names.
+	Keys map[string]string
+	// Target is a struct value, e.g. Row{}, whose type each object is decoded into. The fields are
+	// mapped to the columns as in AddStructs. If nil, the objects are decoded as maps.
+	Target interface{}
+}
+
+// FromNDJSON reads newline-delimited JSON objects from r and appends one row per object with
+// PrepareValues, returning the number of rows read. When decoding by keys, the missing keys are
+// inserted as NULL, the keys not matching any column are ignored, numbers keep their exact text and
+// nested objects and arrays are inserted as JSON text. If SetAutoFlush was called, the rows are inserted
+// while reading and the last ones are flushed at the end.
+func (b *Bulk) FromNDJSON(r io.Reader, opts *NDJSONOptions) (int, error) {
+	if opts == nil {
+		opts = &NDJSONOptions{}
+	}
+	dec := json.NewDecoder(r)
+	dec.UseNumber()
+
+	var targetType reflect.Type
+	var fields [][]int
+	if opts.Target != nil {
+		targetType = reflect.TypeOf(opts.Target)
+		if targetType.Kind() == reflect.Ptr {
+			targetType = targetType.Elem()
+		}
+		if targetType.Kind() != reflect.Struct {
+			return 0, fmt.Errorf("ERROR: The NDJSON target must be a struct, got %T", opts.Target)
+		}
+		var err error
+		if fields, err = b.structFields(targetType); err != nil {
+			return 0, err
+		}
+	}
+
+	// The column of each key
+	keys := map[string]int{}
+	for i, c := range b.inputColumns() {
+		keys[c] = i
+	}
+	if len(opts.Keys) > 0 {
+		for k, c := range opts.Keys {
+			i, ok := keys[c]
+			if !ok {
+				return 0, fmt.Errorf("ERROR: The key %v is mapped to the unknown column %v", k, c)
+			}
+			keys[k] = i
+		}
+		// A column name is only a key if no other key is mapped to it
+		for k := range keys {
+			if _, ok := opts.Keys[k]; !ok && isMappedColumn(opts.Keys, k) {
+				delete(keys, k)
+			}
+		}
+	}
+
+	rows := 0
+	for {
+		vals := make([]interface{}, len(b.inputColumns()))
+		var err error
+		if targetType != nil {
+			target := reflect.New(targetType)
+			if err = dec.Decode(target.Interface()); err == nil {
+				for i, index := range fields {
+					vals[i] = target.Elem().FieldByIndex(index).Interface()
+				}
+			}
+		} else {
+			var obj map[string]interface{}
+			if err = dec.Decode(&obj); err == nil {
+				err = objectValues(obj, keys, vals)
+			}
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return rows, fmt.Errorf("ERROR: NDJSON record %v: %w", rows+1, err)
+		}
+		if err := b.PrepareValues(vals...); err != nil {
+			return rows, err
+		}
+		rows++
+	}
+	if b.autoFlush != nil {
+		return rows, b.Flush()
+	}
+	return rows, nil
+}
+
+// objectValues fills vals with the values of the keys of obj.
+func objectValues(obj map[string]interface{}, keys map[string]int, vals []interface{}) error {
+	for k, v := range obj {
+		i, ok := keys[k]
+		if !ok {
+			continue
+		}
+		switch v.(type) {
+		case map[string]interface{}, []interface{}:
+			var buf bytes.Buffer
+			enc := json.NewEncoder(&buf)
+			enc.SetEscapeHTML(false)
+			if err := enc.Encode(v); err != nil {
+				return err
+			}
+			vals[i] = string(bytes.TrimRight(buf.Bytes(), "\n"))
+		default:
+			vals[i] = v
+		}
+	}
+	return nil
+}
+
+// isMappedColumn reports whether some key of mapping is mapped to column.
+func isMappedColumn(mapping map[string]string, column string) bool {
+	for _, c := range mapping {
+		if c == column {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,85 @@
+package bulk
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestFromNDJSON(t *testing.T) {
+	type row struct {
+		ID   int `db:"id"`
+		Name string
+		Tags string `db:"tags"`
+	}
+	tests := []struct {
+		name     string
+		input    string
+		opts     *NDJSONOptions
+		wantRows int
+		wantArgs []interface{}
+		wantErr  string
+	}{
+		{
+			name:     "keys",
+			input:    `{"id": 10000000000000000001, "name": "a", "tags": ["x", {"y": "<z>"}], "other": 1}` + "\n" + `{"id": 2}`,
+			wantRows: 2,
+			wantArgs: []interface{}{json.Number("10000000000000000001"), "a", `["x",{"y":"<z>"}]`, json.Number("2"), nil, nil},
+		},
+		{
+			name:     "mapped keys",
+			input:    `{"key": 1, "name": "a", "id": 5}`,
+			opts:     &NDJSONOptions{Keys: map[string]string{"key": "id"}},
+			wantRows: 1,
+			wantArgs: []interface{}{json.Number("1"), "a", nil},
+		},
+		{
+			name:     "target",
+			input:    `{"ID": 1, "Name": "a", "Tags": "t"}` + "\n" + `{"ID": 2}`,
+			opts:     &NDJSONOptions{Target: row{}},
+			wantRows: 2,
+			wantArgs: []interface{}{1, "a", "t", 2, "", ""},
+		},
+		{name: "unknown column", input: `{}`, opts: &NDJSONOptions{Keys: map[string]string{"key": "other"}}, wantErr: "mapped to the unknown column other"},
+		{name: "target type", input: `{}`, opts: &NDJSONOptions{Target: 1}, wantErr: "The NDJSON target must be a struct, got int"},
+		{name: "invalid", input: `{"id": 1}` + "\n" + `{"id": `, wantRows: 1, wantErr: "NDJSON record 2"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := New(MySQL)
+			b.Init("t", "id", "name", "tags")
+			rows, err := b.FromNDJSON(strings.NewReader(tt.input), tt.opts)
+			if rows != tt.wantRows {
+				t.Errorf("got %v rows, expected %v", rows, tt.wantRows)
+			}
+			if !checkError(t, err, tt.wantErr) {
+				return
+			}
+			statements, err := b.BuildStatements(false)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(statements[0].Args, tt.wantArgs) {
+				t.Errorf("got %v, expected %v", statements[0].Args, tt.wantArgs)
+			}
+		})
+	}
+}
+
+func TestFromNDJSONAutoFlush(t *testing.T) {
+	r := NewRecorder()
+	b := New(MySQL)
+	b.Init("t", "id")
+	b.SetAutoFlush(context.Background(), r.DB(), 2, false)
+	rows, err := b.FromNDJSON(strings.NewReader(`{"id": 1}`+"\n"+`{"id": 2}`+"\n"+`{"id": 3}`), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The last rows are flushed at the end
+	if got := fmt.Sprint(recordedArgs(r)); rows != 3 || got != "[[1 2] [3]]" {
+		t.Errorf("got %v rows and the statements %v", rows, got)
+	}
+}
@@ -0,0 +1,93 @@
+package bulk
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"io"
+	"reflect"
+	"time"
+)
+
+// SetNullIfZero makes PrepareValues insert NULL instead of the zero values of columns, such as empty
+// strings, zero times and 0.
+func (b *Bulk) SetNullIfZero(columns ...string) {
+	b.nullIfZero = columns
+	b.columnNullIfZero = nil
+}
+
+// normalizeNulls makes the NULL values of row, which is in the buffer, explicit: the nil pointers and the
+// invalid sql.Null* values are replaced with nil, as well as the zero values of the columns set with
+// SetNullIfZero. The other pointers and sql.Null* values are replaced with the value they hold, so every
+// driver receives the same values.
+func (b *Bulk) normalizeNulls(row []interface{}) {
+	if len(b.nullIfZero) > 0 && len(b.columnNullIfZero) != len(b.columns) {
+		b.columnNullIfZero = make([]bool, len(b.columns))
+		for i, c := range b.columns {
+			b.columnNullIfZero[i] = contains(b.nullIfZero, c)
+		}
+	}
+	for i, v := range row {
+		if _, ok := v.(Raw); ok {
+			b.rawValues = true
+			continue
+		}
+		if v == Missing {
+			b.sparse = true
+			continue
+		}
+		if _, ok := v.(io.Reader); ok {
+			if _, ok := v.(driver.Valuer); !ok {
+				// The readers are read when the statements are built
+				b.blobs = true
+				continue
+			}
+		}
+		v = nullValue(v)
+		if v != nil && len(b.nullIfZero) > 0 && b.columnNullIfZero[i] && reflect.ValueOf(v).IsZero() {
+			v = nil
+		}
+		row[i] = v
+	}
+}
+
+// nullValue returns nil if v is NULL, or the value v holds if it's a pointer or a sql.Null* value.
+func nullValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case nil, string, int, int64, int32, float64, bool, []byte, time.Time:
+		return v
+	case sql.NullString:
+		return nullOr(v.Valid, v.String)
+	case sql.NullInt64:
+		return nullOr(v.Valid, v.Int64)
+	case sql.NullInt32:
+		return nullOr(v.Valid, v.Int32)
+	case sql.NullInt16:
+		return nullOr(v.Valid, v.Int16)
+	case sql.NullByte:
+		return nullOr(v.Valid, v.Byte)
+	case sql.NullFloat64:
+		return nullOr(v.Valid, v.Float64)
+	case sql.NullBool:
+		return nullOr(v.Valid, v.Bool)
+	case sql.NullTime:
+		return nullOr(v.Valid, v.Time)
+	}
+	if rv := reflect.ValueOf(v); rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return nil
+		}
+		// The driver converts the pointers which are a driver.Valuer themselves
+		if _, ok := v.(driver.Valuer); !ok {
+			return nullValue(rv.Elem().Interface())
+		}
+	}
+	return v
+}
+
+// nullOr returns v if valid is true, and nil otherwise.
+func nullOr(valid bool, v interface{}) interface{} {
+	if !valid {
+		return nil
+	}
+	return v
+}
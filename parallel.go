@@ -0,0 +1,96 @@
+package bulk
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"sync"
+)
+
+// Errors holds the errors of several batches or rows.
+type Errors []error
+
+func (e Errors) Error() string {
+	msgs := make([]string, len(e))
+	for i, err := range e {
+		msgs[i] = err.Error()
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// Unwrap returns the errors, so errors.Is and errors.As can find any of them.
+func (e Errors) Unwrap() []error {
+	return e
+}
+
+// SetParallelism makes Insert execute up to n batches at the same time, each one on its own connection
+// of the *sql.DB pool. The first failure stops the batches which weren't started yet, and the errors of
+// all the failed batches are returned together as Errors. It's ignored if SetAtomic or SetCommitEvery
+// are enabled, since a transaction runs on a single connection.
+func (b *Bulk) SetParallelism(n int) {
+	b.parallelism = n
+}
+
+// execParallel inserts the rows of batches running up to b.parallelism of them at the same time. The
+// results are added to result in the order of the batches.
+func (b *Bulk) execParallel(ctx context.Context, stmts *stmtCache, batches []batch, head, endStr string, result *Result) error {
+	parent := ctx
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	results := make([]sql.Result, len(batches))
+	errs := make([]error, len(batches))
+	sem := make(chan struct{}, b.parallelism)
+	var wg sync.WaitGroup
+	for i := range batches {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			errs[i] = ctx.Err()
+			break
+		}
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			results[i], errs[i] = b.execBatch(ctx, stmts, batches[i], head, endStr)
+			if errs[i] != nil {
+				cancel()
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	// Only the rows of the batches before the first failed or skipped one are known to be committed
+	var failed Errors
+	committed := true
+	for i, bt := range batches {
+		if results[i] == nil || errs[i] != nil {
+			committed = false
+		}
+		if errs[i] != nil {
+			// The batches cancelled because of another failure are not worth reporting
+			if !errors.Is(errs[i], context.Canceled) || parent.Err() != nil {
+				failed = append(failed, errs[i])
+			}
+			continue
+		}
+		if results[i] == nil {
+			continue
+		}
+		if err := result.add(results[i]); err != nil {
+			failed = append(failed, err)
+			continue
+		}
+		if committed {
+			result.Committed = bt.end
+		}
+	}
+	if len(failed) > 0 {
+		return failed
+	}
+	return nil
+}
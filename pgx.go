@@ -0,0 +1,87 @@
+package bulk
+
+import (
+	"context"
+	"database/sql/driver"
+)
+
+// PgxBatchSender executes statements pipelined in a single round trip, returning the rows affected by each
+// of them until the first one which fails. It's usually implemented with the SendBatch method of a
+// *pgx.Conn or a *pgxpool.Pool of github.com/jackc/pgx, so the statements don't go through database/sql:
+//
+//	func (s sender) SendBatch(ctx context.Context, statements []bulk.Statement) ([]int64, error) {
+//		batch := &pgx.Batch{}
+//		for _, st := range statements {
+//			batch.Queue(st.SQL, st.Args...)
+//		}
+//		br := s.pool.SendBatch(ctx, batch)
+//		defer br.Close()
+//		affected := make([]int64, 0, len(statements))
+//		for range statements {
+//			tag, err := br.Exec()
+//			if err != nil {
+//				return affected, err
+//			}
+//			affected = append(affected, tag.RowsAffected())
+//		}
+//		return affected, br.Close()
+//	}
+type PgxBatchSender interface {
+	SendBatch(ctx context.Context, statements []Statement) (rowsAffected []int64, err error)
+}
+
+// ExecPgx works like Exec, but the statements are sent with s, pipeline of them at a time, or all of them
+// at once if pipeline isn't positive. pgx runs the statements of a batch inside an implicit transaction,
+// so each pipeline is committed or rolled back as a whole, and Result.Committed tells the row from which
+// a failed load can be resumed. The statements are built like BuildStatements does; the retries, the
+// parallelism, the transactions and the continue on error mode don't apply.
+func (b *Bulk) ExecPgx(ctx context.Context, s PgxBatchSender, replaceOnDuplicate bool, pipeline int) (*Result, error) {
+	result := &Result{Committed: b.resumeRow}
+	if err := b.readBlobs(); err != nil {
+		return result, err
+	}
+	removed, err := b.dedupe()
+	if err != nil {
+		return result, err
+	}
+	result.Deduplicated = removed
+	if err := b.sortRows(); err != nil {
+		return result, err
+	}
+	head, endStr, batches, err := b.plan(replaceOnDuplicate)
+	if err != nil {
+		return result, err
+	}
+	if pipeline <= 0 {
+		pipeline = len(batches)
+	}
+	for start := 0; start < len(batches); start += pipeline {
+		end := start + pipeline
+		if end > len(batches) {
+			end = len(batches)
+		}
+		group := batches[start:end]
+		statements := make([]Statement, len(group))
+		for i, bt := range group {
+			str, args, err := b.batchStatement(head, bt, endStr)
+			if err != nil {
+				return result, err
+			}
+			statements[i] = Statement{SQL: str, Args: args}
+		}
+		affected, err := s.SendBatch(ctx, statements)
+		if err != nil {
+			// The whole pipeline was rolled back
+			if len(affected) < len(group) {
+				bt := group[len(affected)]
+				err = &BatchError{Index: bt.index, FirstRow: bt.start, Rows: bt.rows(), Err: err}
+			}
+			return result, err
+		}
+		for _, n := range affected {
+			result.add(driver.RowsAffected(n))
+		}
+		result.Committed = group[len(group)-1].end
+	}
+	return result, b.saveCheckpoint(ctx)
+}
@@ -0,0 +1,88 @@
+package bulk
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// pipelineSender is a PgxBatchSender recording the pipelines. The statement with the argument fail fails.
+type pipelineSender struct {
+	pipelines [][]Statement
+	fail      interface{}
+}
+
+func (s *pipelineSender) SendBatch(ctx context.Context, statements []Statement) ([]int64, error) {
+	s.pipelines = append(s.pipelines, statements)
+	var affected []int64
+	for _, st := range statements {
+		if st.Args[0] == s.fail {
+			return affected, errors.New("boom")
+		}
+		affected = append(affected, int64(len(st.Args)))
+	}
+	return affected, nil
+}
+
+func TestExecPgx(t *testing.T) {
+	s := &pipelineSender{}
+	b := New(Postgres)
+	b.Init("t", "id")
+	b.SetBatchLimit(2)
+	for i := 1; i <= 5; i++ {
+		b.PrepareValues(i)
+	}
+	result, err := b.ExecPgx(context.Background(), s, false, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := [][]Statement{
+		{
+			{SQL: `INSERT INTO "t"("id") VALUES ($1),($2)`, Args: []interface{}{1, 2}},
+			{SQL: `INSERT INTO "t"("id") VALUES ($1),($2)`, Args: []interface{}{3, 4}},
+		},
+		{{SQL: `INSERT INTO "t"("id") VALUES ($1)`, Args: []interface{}{5}}},
+	}
+	if !reflect.DeepEqual(s.pipelines, want) {
+		t.Errorf("got %v, expected %v", s.pipelines, want)
+	}
+	if result.RowsAffected != 5 || result.Batches != 3 || result.Committed != 5 {
+		t.Errorf("got %+v", result)
+	}
+}
+
+func TestExecPgxFailure(t *testing.T) {
+	tests := []struct {
+		name          string
+		pipeline      int
+		wantPipelines int
+		wantCommitted int
+	}{
+		{name: "pipelines", pipeline: 1, wantPipelines: 2, wantCommitted: 2},
+		{name: "single pipeline", pipeline: 0, wantPipelines: 1, wantCommitted: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &pipelineSender{fail: 3}
+			b := New(Postgres)
+			b.Init("t", "id")
+			b.SetBatchLimit(2)
+			for i := 1; i <= 5; i++ {
+				b.PrepareValues(i)
+			}
+			result, err := b.ExecPgx(context.Background(), s, false, tt.pipeline)
+			// The failed pipeline is rolled back as a whole
+			var be *BatchError
+			if !errors.As(err, &be) || be.Index != 1 || be.FirstRow != 2 {
+				t.Fatalf("got the error %v, expected the one of the second batch", err)
+			}
+			if len(s.pipelines) != tt.wantPipelines || result.Committed != tt.wantCommitted {
+				t.Errorf("got %v pipelines and %v committed rows, expected %v and %v", len(s.pipelines), result.Committed, tt.wantPipelines, tt.wantCommitted)
+			}
+			if be.Rows != 2 {
+				t.Errorf("got %v rows in the failed batch, expected 2", be.Rows)
+			}
+		})
+	}
+}
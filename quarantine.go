@@ -0,0 +1,71 @@
+package bulk
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// quarantine is the table receiving the failed rows set with SetQuarantine.
+type quarantine struct {
+	table       string
+	errorColumn string
+	timeColumn  string
+}
+
+// SetQuarantine enables the continue on error mode, see SetContinueOnError, and makes Exec, and the
+// methods built on it, insert the rows which fail in the database, e.g. because of a constraint, into
+// table, so the load goes on and they can be inspected. table has the columns of the Bulk, followed by
+// errorColumn, receiving the text of the error, and timeColumn, receiving the time of the failure, which
+// default to "error_text" and "quarantined_at" if empty. Its columns shouldn't have the constraints the
+// rows failed. The rows are inserted with the db of the load once its statements are executed, and
+// remain in Result.RowErrors. An empty table disables it.
+func (b *Bulk) SetQuarantine(table, errorColumn, timeColumn string) {
+	if table == "" {
+		b.quarantine = nil
+		return
+	}
+	if errorColumn == "" {
+		errorColumn = "error_text"
+	}
+	if timeColumn == "" {
+		timeColumn = "quarantined_at"
+	}
+	b.quarantine = &quarantine{table: table, errorColumn: errorColumn, timeColumn: timeColumn}
+	b.continueOnError = true
+}
+
+// quarantineRows inserts the row errors of result into the quarantine table with db. It returns err, or
+// the error of the insert if err is nil.
+func (b *Bulk) quarantineRows(ctx context.Context, db Execer, result *Result, err error) error {
+	if b.quarantine == nil || len(result.RowErrors) == 0 {
+		return err
+	}
+	q := New(b.dialect)
+	q.SetQuoteIdentifiers(!b.rawIdentifiers)
+	q.Init(b.quarantine.table, append(append([]string{}, b.columns...), b.quarantine.errorColumn, b.quarantine.timeColumn)...)
+	for c, e := range b.exprs {
+		q.SetExpression(c, e)
+	}
+	now := time.Now()
+	for _, r := range result.RowErrors {
+		vals := make([]interface{}, 0, len(b.columns)+2)
+		for i := range b.columns {
+			var v interface{}
+			if i < len(r.Values) && r.Values[i] != Missing {
+				v = r.Values[i]
+			}
+			vals = append(vals, v)
+		}
+		if qErr := q.PrepareValues(append(vals, r.Err.Error(), now)...); qErr != nil {
+			if err != nil {
+				return err
+			}
+			return qErr
+		}
+	}
+	if _, qErr := q.Exec(ctx, db, false); qErr != nil && err == nil {
+		return fmt.Errorf("ERROR: The failed rows can't be inserted into the quarantine table: %w", qErr)
+	}
+	return err
+}
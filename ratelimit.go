@@ -0,0 +1,100 @@
+package bulk
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+)
+
+// Limiter throttles the batches. It's satisfied by *rate.Limiter of golang.org/x/time/rate, and
+// NewRateLimiter returns a simple one.
+type Limiter interface {
+	// WaitN blocks until n events are allowed, or ctx is done.
+	WaitN(ctx context.Context, n int) error
+}
+
+// SetRateLimiter makes every batch wait for l before being executed, so a massive backfill doesn't
+// saturate the database. If rows is true, a batch waits for as many events as rows, limiting the rows per
+// second, and l must allow bursts of a whole batch; otherwise it waits for one event, limiting the batches
+// per second. The time spent waiting isn't part of the batch duration. The backends set with SetBackend
+// aren't throttled. A nil l disables the throttling, which is the default.
+func (b *Bulk) SetRateLimiter(l Limiter, rows bool) {
+	b.limiter = l
+	b.limitRows = rows
+}
+
+// wait waits before executing bt while the controller is paused, and as the rate limiter says.
+func (b *Bulk) wait(ctx context.Context, bt batch) error {
+	if b.controller != nil {
+		if err := b.controller.wait(ctx); err != nil {
+			return err
+		}
+	}
+	if b.limiter == nil {
+		return nil
+	}
+	n := 1
+	if b.limitRows {
+		n = bt.rows()
+	}
+	return b.limiter.WaitN(ctx, n)
+}
+
+// rateLimiter is a token bucket: it holds up to burst tokens, refilled at perSecond tokens per second.
+type rateLimiter struct {
+	mu        sync.Mutex
+	perSecond float64
+	burst     int
+	tokens    float64
+	last      time.Time
+}
+
+// NewRateLimiter returns a Limiter allowing perSecond events per second, in bursts of up to burst events.
+// Both must be positive, as the throttling is disabled by not setting a limiter.
+func NewRateLimiter(perSecond float64, burst int) (Limiter, error) {
+	if !(perSecond > 0) || math.IsInf(perSecond, 1) {
+		return nil, fmt.Errorf("ERROR: The rate of the limiter must be a positive number of events per second, got %v", perSecond)
+	}
+	if burst <= 0 {
+		return nil, fmt.Errorf("ERROR: The burst of the limiter must be positive, got %v", burst)
+	}
+	return &rateLimiter{perSecond: perSecond, burst: burst, tokens: float64(burst), last: time.Now()}, nil
+}
+
+func (l *rateLimiter) WaitN(ctx context.Context, n int) error {
+	if n > l.burst {
+		return fmt.Errorf("ERROR: Waiting for %v events exceeds the burst of the limiter (%v)", n, l.burst)
+	}
+	l.mu.Lock()
+	now := time.Now()
+	l.tokens += now.Sub(l.last).Seconds() * l.perSecond
+	if l.tokens > float64(l.burst) {
+		l.tokens = float64(l.burst)
+	}
+	l.last = now
+	// The tokens are taken now, so the following waiters queue after this one
+	l.tokens -= float64(n)
+	var delay time.Duration
+	if l.tokens < 0 {
+		delay = time.Duration(-l.tokens / l.perSecond * float64(time.Second))
+	}
+	l.mu.Unlock()
+	if delay == 0 {
+		return nil
+	}
+
+	t := time.NewTimer(delay)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		// The tokens which weren't used are given back
+		l.mu.Lock()
+		l.tokens += float64(n)
+		l.mu.Unlock()
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,62 @@
+package bulk
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestNewRateLimiter(t *testing.T) {
+	tests := []struct {
+		name      string
+		perSecond float64
+		burst     int
+		wantErr   string
+	}{
+		{name: "valid", perSecond: 10, burst: 1},
+		{name: "fractional rate", perSecond: 0.5, burst: 1},
+		{name: "zero rate", perSecond: 0, burst: 1, wantErr: "must be a positive number"},
+		{name: "negative rate", perSecond: -1, burst: 1, wantErr: "must be a positive number"},
+		{name: "NaN rate", perSecond: math.NaN(), burst: 1, wantErr: "must be a positive number"},
+		{name: "infinite rate", perSecond: math.Inf(1), burst: 1, wantErr: "must be a positive number"},
+		{name: "zero burst", perSecond: 10, burst: 0, wantErr: "burst of the limiter must be positive"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l, err := NewRateLimiter(tt.perSecond, tt.burst)
+			if checkError(t, err, tt.wantErr) && l == nil {
+				t.Error("got a nil Limiter")
+			}
+		})
+	}
+}
+
+func TestRateLimiterWaitN(t *testing.T) {
+	l, err := NewRateLimiter(1000, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	if err := l.WaitN(ctx, 3); err == nil {
+		t.Error("expected an error waiting for more events than the burst")
+	}
+	// The burst is allowed at once, then the events wait for their tokens
+	start := time.Now()
+	for i := 0; i < 4; i++ {
+		if err := l.WaitN(ctx, 1); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if d := time.Since(start); d < 2*time.Millisecond {
+		t.Errorf("4 events took %v, expected at least 2ms for the 2 after the burst", d)
+	}
+
+	slow, _ := NewRateLimiter(0.001, 1)
+	slow.WaitN(ctx, 1)
+	ctx, cancel := context.WithTimeout(ctx, time.Millisecond)
+	defer cancel()
+	if err := slow.WaitN(ctx, 1); err != context.DeadlineExceeded {
+		t.Errorf("got the error %v, expected %v", err, context.DeadlineExceeded)
+	}
+}
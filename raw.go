@@ -0,0 +1,43 @@
+package bulk
+
+import "fmt"
+
+// Raw is a value written in the statement as a SQL expression instead of being bound as a parameter, e.g.
+// bulk.Raw("NOW()"), bulk.Raw("UUID()") or bulk.Raw("DEFAULT"). The expression is written as given, so it
+// must never contain user input. The backends set with SetBackend don't support it.
+type Raw string
+
+// args returns the values of the rows in bt which are bound as parameters, which are all but the Raw ones,
+// with the values of the expression columns replaced by their arguments.
+func (b *Bulk) args(bt batch) []interface{} {
+	vals := b.values(bt)
+	exprs := b.exprParts()
+	if !b.rawValues && exprs == nil {
+		return vals
+	}
+	args := make([]interface{}, 0, len(vals))
+	for i, v := range vals {
+		if _, ok := v.(Raw); ok {
+			continue
+		}
+		if parts := exprs; parts != nil && parts[i%b.valuesPerRow] != nil {
+			args = append(args, exprArgs(parts[i%b.valuesPerRow], v)...)
+			continue
+		}
+		args = append(args, v)
+	}
+	return args
+}
+
+// checkNoRaw returns an error if some buffered value is Raw.
+func (b *Bulk) checkNoRaw() error {
+	if !b.rawValues {
+		return nil
+	}
+	for i, v := range b.vals {
+		if _, ok := v.(Raw); ok {
+			return fmt.Errorf("ERROR: The Raw value of the row %v can't be loaded with a backend", i/b.valuesPerRow)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,224 @@
+package bulk
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"io"
+	"reflect"
+	"sync"
+)
+
+// Recorder is a fake database which records the statements executed on it instead of running them, so the
+// code loading rows with a Bulk can be unit tested without a database. The transactions started on it are
+// recorded as the statements "BEGIN", "COMMIT" and "ROLLBACK". The arguments are recorded as given to the
+// driver, without converting them. It's safe for concurrent use.
+type Recorder struct {
+	mu         sync.Mutex
+	statements []Statement
+	respond    func(s Statement) (int64, error)
+	db         *sql.DB
+}
+
+// NewRecorder returns an empty Recorder.
+func NewRecorder() *Recorder {
+	r := &Recorder{}
+	r.db = sql.OpenDB(recorderConnector{r})
+	return r
+}
+
+// DB returns the handle to give to Insert and the other methods executing statements.
+func (r *Recorder) DB() *sql.DB {
+	return r.db
+}
+
+// SetResponder makes the statements return the number of rows affected and the error returned by respond.
+// By default they succeed affecting no rows.
+func (r *Recorder) SetResponder(respond func(s Statement) (rowsAffected int64, err error)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.respond = respond
+}
+
+// Statements returns the statements recorded since the Recorder was created or reset, in execution order.
+func (r *Recorder) Statements() []Statement {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Statement(nil), r.statements...)
+}
+
+// Reset removes the recorded statements.
+func (r *Recorder) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.statements = nil
+}
+
+// Expect reports how the recorded statements differ from expected, comparing their SQL and their
+// arguments, or returns nil if they're the same.
+func (r *Recorder) Expect(expected ...Statement) error {
+	return r.expect(expected, true)
+}
+
+// ExpectSQL works like Expect, but only compares the SQL of the statements.
+func (r *Recorder) ExpectSQL(expected ...string) error {
+	statements := make([]Statement, len(expected))
+	for i, s := range expected {
+		statements[i].SQL = s
+	}
+	return r.expect(statements, false)
+}
+
+// expect compares the recorded statements with expected, and their arguments if args is true.
+func (r *Recorder) expect(expected []Statement, args bool) error {
+	recorded := r.Statements()
+	for i, s := range recorded {
+		if i == len(expected) {
+			return fmt.Errorf("ERROR: Unexpected statement %v: %q", i, s.SQL)
+		}
+		if s.SQL != expected[i].SQL {
+			return fmt.Errorf("ERROR: Statement %v is %q, expected %q", i, s.SQL, expected[i].SQL)
+		}
+		if args && !reflect.DeepEqual(s.Args, expected[i].Args) && len(s.Args)+len(expected[i].Args) > 0 {
+			return fmt.Errorf("ERROR: Statement %v has the arguments %v, expected %v", i, s.Args, expected[i].Args)
+		}
+	}
+	if len(expected) > len(recorded) {
+		return fmt.Errorf("ERROR: Missing statement %v: %q", len(recorded), expected[len(recorded)].SQL)
+	}
+	return nil
+}
+
+// record records the statement str with args, returning the rows affected by it.
+func (r *Recorder) record(str string, args []driver.NamedValue) (int64, error) {
+	s := Statement{SQL: str}
+	for _, a := range args {
+		s.Args = append(s.Args, a.Value)
+	}
+	r.mu.Lock()
+	r.statements = append(r.statements, s)
+	respond := r.respond
+	r.mu.Unlock()
+	if respond == nil {
+		return 0, nil
+	}
+	return respond(s)
+}
+
+// recorderConnector opens the connections of a Recorder.
+type recorderConnector struct {
+	r *Recorder
+}
+
+func (c recorderConnector) Connect(context.Context) (driver.Conn, error) {
+	return recorderConn(c), nil
+}
+
+func (c recorderConnector) Driver() driver.Driver {
+	return recorderDriver(c)
+}
+
+// recorderDriver is the driver of a Recorder.
+type recorderDriver struct {
+	r *Recorder
+}
+
+func (d recorderDriver) Open(string) (driver.Conn, error) {
+	return recorderConn(d), nil
+}
+
+// recorderConn is a connection to a Recorder. It implements the transactions too.
+type recorderConn struct {
+	r *Recorder
+}
+
+func (c recorderConn) Prepare(query string) (driver.Stmt, error) {
+	return recorderStmt{r: c.r, query: query}, nil
+}
+
+func (c recorderConn) Close() error {
+	return nil
+}
+
+func (c recorderConn) Begin() (driver.Tx, error) {
+	_, err := c.r.record("BEGIN", nil)
+	return c, err
+}
+
+func (c recorderConn) Commit() error {
+	_, err := c.r.record("COMMIT", nil)
+	return err
+}
+
+func (c recorderConn) Rollback() error {
+	_, err := c.r.record("ROLLBACK", nil)
+	return err
+}
+
+// CheckNamedValue accepts every argument as is.
+func (c recorderConn) CheckNamedValue(*driver.NamedValue) error {
+	return nil
+}
+
+// recorderStmt is a statement prepared on a Recorder, recorded on every execution.
+type recorderStmt struct {
+	r     *Recorder
+	query string
+}
+
+func (s recorderStmt) Close() error {
+	return nil
+}
+
+func (s recorderStmt) NumInput() int {
+	return -1
+}
+
+func (s recorderStmt) Exec(args []driver.Value) (driver.Result, error) {
+	return s.ExecContext(context.Background(), namedValues(args))
+}
+
+func (s recorderStmt) ExecContext(_ context.Context, args []driver.NamedValue) (driver.Result, error) {
+	n, err := s.r.record(s.query, args)
+	if err != nil {
+		return nil, err
+	}
+	return driver.RowsAffected(n), nil
+}
+
+func (s recorderStmt) Query(args []driver.Value) (driver.Rows, error) {
+	return s.QueryContext(context.Background(), namedValues(args))
+}
+
+// QueryContext records the query, which returns no rows.
+func (s recorderStmt) QueryContext(_ context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	if _, err := s.r.record(s.query, args); err != nil {
+		return nil, err
+	}
+	return recorderRows{}, nil
+}
+
+// namedValues returns args as positional named values.
+func namedValues(args []driver.Value) []driver.NamedValue {
+	named := make([]driver.NamedValue, len(args))
+	for i, v := range args {
+		named[i] = driver.NamedValue{Ordinal: i + 1, Value: v}
+	}
+	return named
+}
+
+// recorderRows are the empty rows returned by the queries of a Recorder.
+type recorderRows struct{}
+
+func (recorderRows) Columns() []string {
+	return nil
+}
+
+func (recorderRows) Close() error {
+	return nil
+}
+
+func (recorderRows) Next([]driver.Value) error {
+	return io.EOF
+}
@@ -0,0 +1,78 @@
+package bulk
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestRecorder(t *testing.T) {
+	r := NewRecorder()
+	b := New(Postgres)
+	b.Init("t", "id")
+	b.SetAtomic(true)
+	b.PrepareValues(1)
+	b.PrepareValues(2)
+	if err := b.Insert(r.DB(), false); err != nil {
+		t.Fatal(err)
+	}
+	insert := Statement{SQL: `INSERT INTO "t"("id") VALUES ($1),($2)`, Args: []interface{}{1, 2}}
+	if err := r.Expect(Statement{SQL: "BEGIN"}, insert, Statement{SQL: "COMMIT"}); err != nil {
+		t.Error(err)
+	}
+	if err := r.ExpectSQL("BEGIN", insert.SQL, "COMMIT"); err != nil {
+		t.Error(err)
+	}
+
+	tests := []struct {
+		name     string
+		expected []Statement
+		wantErr  string
+	}{
+		{name: "sql", expected: []Statement{{SQL: "BEGIN"}, {SQL: "INSERT"}, {SQL: "COMMIT"}}, wantErr: `Statement 1 is "INSERT INTO \"t\"(\"id\") VALUES ($1),($2)", expected "INSERT"`},
+		{name: "args", expected: []Statement{{SQL: "BEGIN"}, {SQL: insert.SQL, Args: []interface{}{1, 3}}, {SQL: "COMMIT"}}, wantErr: "Statement 1 has the arguments [1 2], expected [1 3]"},
+		{name: "unexpected", expected: []Statement{{SQL: "BEGIN"}, insert}, wantErr: `Unexpected statement 2: "COMMIT"`},
+		{name: "missing", expected: []Statement{{SQL: "BEGIN"}, insert, {SQL: "COMMIT"}, {SQL: "SELECT 1"}}, wantErr: `Missing statement 3: "SELECT 1"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checkError(t, r.Expect(tt.expected...), tt.wantErr)
+		})
+	}
+
+	r.Reset()
+	if err := r.Expect(); err != nil {
+		t.Errorf("got %v after Reset", err)
+	}
+}
+
+func TestRecorderResponder(t *testing.T) {
+	r := NewRecorder()
+	r.SetResponder(func(s Statement) (int64, error) {
+		if len(s.Args) > 0 && s.Args[0] == 3 {
+			return 0, errors.New("boom")
+		}
+		return 5, nil
+	})
+	b := New(MySQL)
+	b.Init("t", "id")
+	b.SetBatchLimit(2)
+	for i := 1; i <= 3; i++ {
+		b.PrepareValues(i)
+	}
+	result, err := b.Exec(context.Background(), r.DB(), false)
+	checkError(t, err, "boom")
+	if result.RowsAffected != 5 || result.Committed != 2 {
+		t.Errorf("got %+v, expected the 5 rows affected by the first statement", result)
+	}
+
+	// The failed transaction is rolled back
+	r.Reset()
+	b.SetAtomic(true)
+	_, err = b.Exec(context.Background(), r.DB(), false)
+	checkError(t, err, "boom")
+	err = r.ExpectSQL("BEGIN", "INSERT INTO `t`(`id`) VALUES (?),(?)", "INSERT INTO `t`(`id`) VALUES (?)", "ROLLBACK")
+	if err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,32 @@
+package bulk
+
+import "database/sql"
+
+// Result summarizes the statements executed by Exec.
+type Result struct {
+	RowsAffected int64        // Sum of the rows affected by every batch. MySQL counts 2 for each updated duplicate
+	Batches      int          // Number of statements executed
+	Results      []sql.Result // Result of each batch, in execution order
+	// Committed is the number of rows, counting from the first one, which are known to be committed (just
+	// executed if db is a *sql.Tx). After a failure, the load can be resumed from there with SetResumeRow.
+	Committed int
+	// RowErrors holds the rows which failed in the continue on error mode, see SetContinueOnError.
+	RowErrors []*RowError
+	// Deduplicated is the number of buffered rows removed as duplicates, see DedupeBy.
+	Deduplicated int
+}
+
+// add records the result of an executed batch.
+func (r *Result) add(res sql.Result) error {
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if p, ok := res.(*partialResult); ok {
+		r.RowErrors = append(r.RowErrors, p.rowErrors...)
+	}
+	r.RowsAffected += affected
+	r.Batches++
+	r.Results = append(r.Results, res)
+	return nil
+}
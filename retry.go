@@ -0,0 +1,81 @@
+package bulk
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+)
+
+// RetryPolicy decides how a failed batch is retried. Retrying is only safe when the insert doesn't run
+// inside a transaction, because both MySQL and Postgres abort the transaction on a deadlock or a
+// serialization failure.
+type RetryPolicy struct {
+	MaxAttempts int                  // Number of attempts per batch, including the first one
+	Backoff     time.Duration        // Wait before the first retry, doubled after every attempt
+	MaxBackoff  time.Duration        // Upper bound of the wait between attempts, 0 means no bound
+	Retryable   func(err error) bool // Reports whether err is transient. If nil, IsRetryable is used
+}
+
+// SetRetryPolicy sets the policy used to retry the batches which fail with a transient error. A nil
+// policy disables the retries, which is the default.
+func (b *Bulk) SetRetryPolicy(p *RetryPolicy) {
+	b.retryPolicy = p
+}
+
+// sqlStater is implemented by the errors of the Postgres drivers (lib/pq and pgx).
+type sqlStater interface {
+	SQLState() string
+}
+
+// IsRetryable reports whether err is a transient error worth retrying: a MySQL deadlock (1213) or lock
+// wait timeout (1205), or a Postgres serialization failure (40001) or deadlock (40P01). The package
+// doesn't depend on any driver, so MySQL errors are recognized by their message.
+func IsRetryable(err error) bool {
+	if err == nil {
+		return false
+	}
+	var s sqlStater
+	if errors.As(err, &s) {
+		switch s.SQLState() {
+		case "40001", "40P01":
+			return true
+		}
+		return false
+	}
+	msg := err.Error()
+	return strings.Contains(msg, "Error 1213") || strings.Contains(msg, "Error 1205") ||
+		strings.Contains(msg, "Deadlock found")
+}
+
+// retry calls fn until it succeeds, it returns a non retryable error or the attempts of the retry policy
+// are exhausted. It waits between attempts as the policy says, unless ctx is done.
+func (b *Bulk) retry(ctx context.Context, fn func() error) error {
+	p := b.retryPolicy
+	if p == nil || p.MaxAttempts <= 1 {
+		return fn()
+	}
+	retryable := p.Retryable
+	if retryable == nil {
+		retryable = IsRetryable
+	}
+
+	wait := p.Backoff
+	for attempt := 1; ; attempt++ {
+		err := fn()
+		if err == nil || attempt >= p.MaxAttempts || !retryable(err) {
+			return err
+		}
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+		wait *= 2
+		if p.MaxBackoff > 0 && wait > p.MaxBackoff {
+			wait = p.MaxBackoff
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package bulk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+// stateError is an error carrying a SQLSTATE, as the errors of the Postgres drivers.
+type stateError string
+
+func (e stateError) Error() string {
+	return "pq: " + string(e)
+}
+
+func (e stateError) SQLState() string {
+	return string(e)
+}
+
+func TestIsRetryable(t *testing.T) {
+	tests := []struct {
+		err  error
+		want bool
+	}{
+		{err: nil, want: false},
+		{err: errors.New("Error 1213 (40001): Deadlock found when trying to get lock"), want: true},
+		{err: errors.New("Error 1205: Lock wait timeout exceeded"), want: true},
+		{err: fmt.Errorf("batch: %w", errors.New("Deadlock found")), want: true},
+		{err: errors.New("Error 1062: Duplicate entry"), want: false},
+		{err: stateError("40001"), want: true},
+		{err: fmt.Errorf("batch: %w", stateError("40P01")), want: true},
+		{err: stateError("23505"), want: false},
+	}
+	for _, tt := range tests {
+		if got := IsRetryable(tt.err); got != tt.want {
+			t.Errorf("IsRetryable(%v) = %v, expected %v", tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestRetry(t *testing.T) {
+	deadlock := errors.New("Error 1213: Deadlock found")
+	tests := []struct {
+		name         string
+		policy       *RetryPolicy
+		errs         []error // Errors of the successive executions, then they succeed
+		wantAttempts int
+		wantErr      string
+	}{
+		{name: "no policy", errs: []error{deadlock}, wantAttempts: 1, wantErr: "Deadlock found"},
+		{name: "retried", policy: &RetryPolicy{MaxAttempts: 3}, errs: []error{deadlock, deadlock}, wantAttempts: 3},
+		{name: "exhausted", policy: &RetryPolicy{MaxAttempts: 2}, errs: []error{deadlock, deadlock}, wantAttempts: 2, wantErr: "Deadlock found"},
+		{name: "not retryable", policy: &RetryPolicy{MaxAttempts: 3}, errs: []error{errors.New("syntax error")}, wantAttempts: 1, wantErr: "syntax error"},
+		{
+			name:         "custom",
+			policy:       &RetryPolicy{MaxAttempts: 3, Retryable: func(err error) bool { return err.Error() == "busy" }},
+			errs:         []error{errors.New("busy"), deadlock},
+			wantAttempts: 2,
+			wantErr:      "Deadlock found",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewRecorder()
+			attempts := 0
+			r.SetResponder(func(s Statement) (int64, error) {
+				attempts++
+				if attempts <= len(tt.errs) {
+					return 0, tt.errs[attempts-1]
+				}
+				return 1, nil
+			})
+			b := New(MySQL)
+			b.Init("t", "id")
+			b.SetRetryPolicy(tt.policy)
+			b.PrepareValues(1)
+			err := b.Insert(r.DB(), false)
+			checkError(t, err, tt.wantErr)
+			if attempts != tt.wantAttempts {
+				t.Errorf("got %v attempts, expected %v", attempts, tt.wantAttempts)
+			}
+		})
+	}
+}
+
+func TestRetryCanceled(t *testing.T) {
+	r := NewRecorder()
+	r.SetResponder(func(s Statement) (int64, error) {
+		return 0, errors.New("Error 1213: Deadlock found")
+	})
+	b := New(MySQL)
+	b.Init("t", "id")
+	b.SetRetryPolicy(&RetryPolicy{MaxAttempts: 3, Backoff: time.Hour})
+	b.PrepareValues(1)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	// The wait before the retry ends with ctx
+	err := b.InsertContext(ctx, r.DB(), false)
+	checkError(t, err, "Deadlock found")
+	if n := len(r.Statements()); n != 1 {
+		t.Errorf("got %v attempts, expected 1", n)
+	}
+}
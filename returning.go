@@ -0,0 +1,115 @@
+package bulk
+
+import (
+	"context"
+	"fmt"
+)
+
+// Returner is implemented by the dialects which can return the generated values of the inserted rows
+// with a RETURNING clause, such as Postgres and SQLite 3.35+.
+type Returner interface {
+	// Returning returns the clause appended to the insert statement to return column.
+	Returning(column string) string
+}
+
+func (postgresDialect) Returning(column string) string {
+	return " RETURNING " + column
+}
+
+func (sqliteDialect) Returning(column string) string {
+	return " RETURNING " + column
+}
+
+// InsertReturning inserts the data like InsertContext, without any upsert clause, and returns the value
+// of the generated column (usually the auto-increment primary key) of every inserted row, in the same
+// order as the rows. The statements are those Exec runs, with the hints, comment, CTE and session of the
+// Bulk, followed by the clause returning column. As every row must be inserted, SetIgnoreDuplicates and
+// SetReplaceInto can't be used.
+//
+// Dialects implementing Returner use a RETURNING clause. Otherwise, the ids are computed from the
+// LastInsertId and the number of rows affected by each statement, which is only correct for MySQL tables
+// with auto_increment_increment = 1 and innodb_autoinc_lock_mode 0 or 1, where the ids of a multi-row
+// INSERT are consecutive.
+func (b *Bulk) InsertReturning(ctx context.Context, db Execer, column string) ([]int64, error) {
+	if b.ignoreDuplicates || b.replaceInto {
+		return nil, fmt.Errorf("ERROR: InsertReturning can't skip nor replace the duplicates")
+	}
+	if err := validateIdentifier(column); err != nil {
+		return nil, err
+	}
+	db = sqlTx(db)
+	setup, teardown, err := b.aroundStatements()
+	if err != nil {
+		return nil, err
+	}
+	if len(setup)+len(teardown) == 0 {
+		return b.insertReturning(ctx, db, column)
+	}
+	var ids []int64
+	err = onSession(ctx, db, setup, teardown, func(db Execer) error {
+		var err error
+		ids, err = b.insertReturning(ctx, db, column)
+		return err
+	})
+	return ids, err
+}
+
+// insertReturning works like InsertReturning, without the session statements.
+func (b *Bulk) insertReturning(ctx context.Context, db Execer, column string) ([]int64, error) {
+	head, endStr, batches, err := b.plan(false)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]int64, 0, b.rows)
+	returner, ok := b.dialect.(Returner)
+	for _, bt := range batches {
+		if ok {
+			str, args, err := b.batchStatement(head, bt, endStr+returner.Returning(b.quote(column)))
+			if err != nil {
+				return ids, err
+			}
+			rows, err := db.QueryContext(ctx, str, args...)
+			if err != nil {
+				return ids, err
+			}
+			for rows.Next() {
+				var id int64
+				if err := rows.Scan(&id); err != nil {
+					rows.Close()
+					return ids, err
+				}
+				ids = append(ids, id)
+			}
+			rows.Close()
+			if err := rows.Err(); err != nil {
+				return ids, err
+			}
+			continue
+		}
+
+		str, args, err := b.batchStatement(head, bt, endStr)
+		if err != nil {
+			return ids, err
+		}
+		res, err := db.ExecContext(ctx, str, args...)
+		if err != nil {
+			return ids, err
+		}
+		first, err := res.LastInsertId()
+		if err != nil {
+			return ids, err
+		}
+		affected, err := res.RowsAffected()
+		if err != nil {
+			return ids, err
+		}
+		if affected != int64(bt.rows()) {
+			return ids, fmt.Errorf("ERROR: Inserted %v rows of %v, the generated ids can't be computed", affected, bt.rows())
+		}
+		for i := int64(0); i < affected; i++ {
+			ids = append(ids, first+i)
+		}
+	}
+	return ids, nil
+}
@@ -0,0 +1,99 @@
+package bulk
+
+import (
+	"context"
+	"testing"
+)
+
+func TestInsertReturning(t *testing.T) {
+	tests := []struct {
+		name    string
+		setup   func(b *Bulk)
+		want    []Statement
+		wantErr string
+	}{
+		{
+			name: "plain",
+			want: []Statement{{SQL: `INSERT INTO "t"("id", "name") VALUES ($1,$2),($3,$4) RETURNING "id"`, Args: []interface{}{1, "a", 2, "b"}}},
+		},
+		{
+			name:  "batches",
+			setup: func(b *Bulk) { b.SetBatchLimit(2) },
+			want: []Statement{
+				{SQL: `INSERT INTO "t"("id", "name") VALUES ($1,$2) RETURNING "id"`, Args: []interface{}{1, "a"}},
+				{SQL: `INSERT INTO "t"("id", "name") VALUES ($1,$2) RETURNING "id"`, Args: []interface{}{2, "b"}},
+			},
+		},
+		{
+			name: "hints, comment and interpolation",
+			setup: func(b *Bulk) {
+				b.SetHints("x")
+				b.SetComment(map[string]string{"job": "load"}, false)
+				b.SetInterpolate(true)
+			},
+			want: []Statement{{SQL: `INSERT /*+ x */ INTO "t"("id", "name") VALUES (1,'a'),(2,'b') /*job='load'*/ RETURNING "id"`}},
+		},
+		{
+			name: "session",
+			setup: func(b *Bulk) {
+				b.SetSession([]string{"SET a = 1"}, []string{"RESET a"})
+			},
+			want: []Statement{
+				{SQL: "SET a = 1"},
+				{SQL: `INSERT INTO "t"("id", "name") VALUES ($1,$2),($3,$4) RETURNING "id"`, Args: []interface{}{1, "a", 2, "b"}},
+				{SQL: "RESET a"},
+			},
+		},
+		{
+			name:    "ignored duplicates",
+			setup:   func(b *Bulk) { b.SetConflictTarget("id"); b.SetIgnoreDuplicates(true) },
+			wantErr: "can't skip nor replace the duplicates",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewRecorder()
+			b := New(Postgres)
+			b.Init("t", "id", "name")
+			if tt.setup != nil {
+				tt.setup(b)
+			}
+			b.PrepareValues(1, "a")
+			b.PrepareValues(2, "b")
+			_, err := b.InsertReturning(context.Background(), r.DB(), "id")
+			if !checkError(t, err, tt.wantErr) {
+				return
+			}
+			if err := r.Expect(tt.want...); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+func TestInsertReturningInvalidColumn(t *testing.T) {
+	b := New(Postgres)
+	b.Init("t", "id")
+	b.PrepareValues(1)
+	if _, err := b.InsertReturning(context.Background(), NewRecorder().DB(), `id"`); err == nil {
+		t.Error("expected an error for an invalid column")
+	}
+}
+
+func TestCTEReturning(t *testing.T) {
+	r := NewRecorder()
+	b := New(Postgres)
+	b.Init("t", "id", "name")
+	b.SetCTE(&CTE{Where: "id > 0"})
+	b.PrepareValues(1, "a")
+	if _, err := b.InsertReturning(context.Background(), r.DB(), "id"); err != nil {
+		t.Fatal(err)
+	}
+	err := r.Expect(Statement{
+		SQL:  `WITH "new_rows"("id", "name") AS (VALUES ($1,$2)) INSERT INTO "t"("id", "name") SELECT "id", "name" FROM "new_rows" WHERE id > 0 RETURNING "id"`,
+		Args: []interface{}{1, "a"},
+	})
+	if err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,38 @@
+package bulk
+
+// RowHook is called with the values of each row before it's buffered. It returns the values to buffer,
+// which may be vals modified in place or a new slice, and whether to keep the row: a row which isn't kept
+// is skipped. A row with an error is rejected, it's skipped too and reported by Rejected.
+type RowHook func(vals []interface{}) ([]interface{}, bool, error)
+
+// BeforeRow adds a hook called by PrepareValues, and the methods built on it, with the values of each row
+// before they are buffered, e.g. to clean up the fields of a file. The hooks are called in the order they
+// were added, each one with the values returned by the previous one, and receive the values of the
+// columns given to Init only.
+func (b *Bulk) BeforeRow(hook RowHook) {
+	b.rowHooks = append(b.rowHooks, hook)
+}
+
+// Rejected returns the rows rejected by the BeforeRow hooks since Init or Reset, with the values given to
+// the hook which rejected them, and the ones which failed the validation in the lenient mode (see
+// SetLenientValidation). Their Row is the index of the row among the ones received.
+func (b *Bulk) Rejected() []*RowError {
+	return b.rejected
+}
+
+// runRowHooks runs the BeforeRow hooks on vals, the received row row, returning the values to buffer and
+// whether to keep them.
+func (b *Bulk) runRowHooks(row int, vals []interface{}) ([]interface{}, bool) {
+	for _, hook := range b.rowHooks {
+		out, keep, err := hook(vals)
+		if err != nil {
+			b.rejected = append(b.rejected, &RowError{Row: row, Values: vals, Err: err})
+			return nil, false
+		}
+		if !keep {
+			return nil, false
+		}
+		vals = out
+	}
+	return vals, true
+}
@@ -0,0 +1,41 @@
+package bulk
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// AddFromRows appends every row of rows, which must have as many columns as given to Init, in the same
+// order, and returns the number of rows read. The caller still has to close rows. If SetAutoFlush was
+// called, the rows are inserted while reading and the last ones are flushed at the end.
+func (b *Bulk) AddFromRows(rows *sql.Rows) (int, error) {
+	columns, err := rows.Columns()
+	if err != nil {
+		return 0, err
+	}
+	if required := len(b.inputColumns()); len(columns) != required {
+		return 0, fmt.Errorf("%w: The rows have %v columns, Required: %v", ErrValueCountMismatch, len(columns), required)
+	}
+	n := 0
+	for rows.Next() {
+		vals := make([]interface{}, len(columns))
+		dest := make([]interface{}, len(columns))
+		for i := range vals {
+			dest[i] = &vals[i]
+		}
+		if err := rows.Scan(dest...); err != nil {
+			return n, err
+		}
+		if err := b.PrepareValues(vals...); err != nil {
+			return n, err
+		}
+		n++
+	}
+	if err := rows.Err(); err != nil {
+		return n, err
+	}
+	if b.autoFlush != nil {
+		return n, b.Flush()
+	}
+	return n, nil
+}
@@ -0,0 +1,82 @@
+package bulk
+
+import (
+	"context"
+	"sync"
+)
+
+// SafeBulk wraps a Bulk so rows can be added from several goroutines at the same time. Each insert takes
+// a snapshot of the rows buffered so far and removes them from the buffer, so the producers can keep
+// adding rows while the snapshot is being inserted.
+type SafeBulk struct {
+	mu sync.Mutex
+	b  *Bulk
+}
+
+// NewSafe returns a SafeBulk wrapping b, which must be already initialized and configured. b must not be
+// used directly afterwards.
+func NewSafe(b *Bulk) *SafeBulk {
+	if b.stats == nil {
+		b.stats = &statsRecorder{}
+	}
+	return &SafeBulk{b: b}
+}
+
+// PrepareValues works like Bulk.PrepareValues and it's safe for concurrent use.
+func (s *SafeBulk) PrepareValues(vals ...interface{}) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.b.PrepareValues(vals...)
+}
+
+// Insert inserts the rows buffered so far. See Exec.
+func (s *SafeBulk) Insert(db Execer, replaceOnDuplicate bool) error {
+	_, err := s.Exec(context.Background(), db, replaceOnDuplicate)
+	return err
+}
+
+// InsertContext inserts the rows buffered so far with ctx. See Exec.
+func (s *SafeBulk) InsertContext(ctx context.Context, db Execer, replaceOnDuplicate bool) error {
+	_, err := s.Exec(ctx, db, replaceOnDuplicate)
+	return err
+}
+
+// Exec inserts a snapshot of the rows buffered so far, like Bulk.Exec, and removes them from the buffer.
+// The rows added meanwhile are left for the next call. If the insert fails, the rows which weren't
+// committed are put back in front of the buffer.
+func (s *SafeBulk) Exec(ctx context.Context, db Execer, replaceOnDuplicate bool) (*Result, error) {
+	snapshot := s.snapshot()
+	if snapshot.rows == 0 {
+		// Nothing to insert yet, which is normal when the producers are slower than the inserts
+		return &Result{}, nil
+	}
+	result, err := snapshot.Exec(ctx, db, replaceOnDuplicate)
+	if err != nil {
+		s.restore(snapshot.vals[result.Committed*snapshot.valuesPerRow:])
+	}
+	return result, err
+}
+
+// Stats returns the statistics of the last insert, see Bulk.Stats.
+func (s *SafeBulk) Stats() Stats {
+	return s.b.Stats()
+}
+
+// snapshot returns a copy of the Bulk holding the buffered rows, which are removed from the buffer.
+func (s *SafeBulk) snapshot() *Bulk {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	snapshot := *s.b
+	s.b.vals = make([]interface{}, 0, cap(snapshot.vals))
+	s.b.rows = 0
+	s.b.resumeRow = 0
+	return &snapshot
+}
+
+// restore puts vals back in front of the buffer.
+func (s *SafeBulk) restore(vals []interface{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.b.vals = append(append(make([]interface{}, 0, len(vals)+len(s.b.vals)), vals...), s.b.vals...)
+	s.b.rows = len(s.b.vals) / s.b.valuesPerRow
+}
@@ -0,0 +1,103 @@
+package bulk
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// SchemaQuerier is implemented by the dialects whose databases describe their tables in
+// information_schema, such as MySQL, Postgres, SQL Server and ClickHouse.
+type SchemaQuerier interface {
+	// CurrentSchema returns the SQL expression of the schema of the unqualified tables, e.g. "DATABASE()".
+	CurrentSchema() string
+}
+
+func (mysqlDialect) CurrentSchema() string {
+	return "DATABASE()"
+}
+
+func (postgresDialect) CurrentSchema() string {
+	return "current_schema()"
+}
+
+func (sqlServerDialect) CurrentSchema() string {
+	return "SCHEMA_NAME()"
+}
+
+func (clickHouseDialect) CurrentSchema() string {
+	return "currentDatabase()"
+}
+
+// ValidateSchema checks with information_schema that the table exists and has every column given to
+// Init, comparing the names ignoring the case, so a load which would fail is detected before sending any
+// row. If strict is true, the table must have exactly the columns of Init, in the same order.
+func (b *Bulk) ValidateSchema(ctx context.Context, db Querier, strict bool) error {
+	if b.initErr != nil {
+		return b.initErr
+	}
+	if b.table == "" {
+		return ErrNotInitialized
+	}
+	sq, ok := b.dialect.(SchemaQuerier)
+	if !ok {
+		return fmt.Errorf("ERROR: The dialect %T can't query information_schema", b.dialect)
+	}
+	schema, table := sq.CurrentSchema(), b.table
+	args := []interface{}{}
+	if i := strings.LastIndexByte(table, '.'); i >= 0 {
+		schema = b.dialect.Placeholder(2)
+		args = append(args, table[i+1:], table[:i])
+		table = table[i+1:]
+	} else {
+		args = append(args, table)
+	}
+	str := "SELECT column_name FROM information_schema.columns WHERE table_name = " + b.dialect.Placeholder(1) +
+		" AND table_schema = " + schema + " ORDER BY ordinal_position"
+	rows, err := db.QueryContext(ctx, str, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	var existing []string
+	for rows.Next() {
+		var c string
+		if err := rows.Scan(&c); err != nil {
+			return err
+		}
+		existing = append(existing, c)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	if len(existing) == 0 {
+		return fmt.Errorf("ERROR: The table %v doesn't exist", b.table)
+	}
+	var missing []string
+	for _, c := range b.columns {
+		found := false
+		for _, e := range existing {
+			if strings.EqualFold(c, e) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			missing = append(missing, c)
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("ERROR: The table %v has no columns %v, its columns are %v", b.table, missing, existing)
+	}
+	if strict {
+		match := len(existing) == len(b.columns)
+		for i := 0; match && i < len(existing); i++ {
+			match = strings.EqualFold(existing[i], b.columns[i])
+		}
+		if !match {
+			return fmt.Errorf("ERROR: The columns %v don't match the columns of the table %v: %v", b.columns, b.table, existing)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+package bulk
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+)
+
+// SetSession sets the statements run on the connection of Exec, and the methods built on it, before
+// inserting the rows, e.g. "SET unique_checks=0", and the ones run afterwards to restore the session,
+// e.g. "SET unique_checks=1". The restoring statements run even if the setup or the load fail, or ctx
+// is done. As the session belongs to a single connection, a *sql.DB is asked for one for the whole load,
+// so the batches run one after the other even with SetParallelism. If restoring fails, the connection
+// is discarded instead of being returned to the pool with the changed session. The automatic flushes of
+// SetAutoFlush don't run the statements.
+func (b *Bulk) SetSession(setup, teardown []string) {
+	b.sessionSetup = setup
+	b.sessionTeardown = teardown
+}
+
+// execSession runs the load with execWith between the setup and teardown statements, on a single
+// connection.
+func (b *Bulk) execSession(ctx context.Context, db Execer, setup, teardown []string, replaceOnDuplicate bool) (*Result, error) {
+	result := &Result{Committed: b.resumeRow}
+	err := onSession(ctx, db, setup, teardown, func(db Execer) error {
+		stmts := newStmtCache(db)
+		defer stmts.close()
+		var err error
+		result, err = b.execWith(ctx, stmts, replaceOnDuplicate)
+		return err
+	})
+	return result, err
+}
+
+// onSession runs fn between the setup and teardown statements, on a single connection of db.
+func onSession(ctx context.Context, db Execer, setup, teardown []string, fn func(db Execer) error) (err error) {
+	var conn *sql.Conn
+	if c, ok := db.(connector); ok {
+		if conn, err = c.Conn(ctx); err != nil {
+			return err
+		}
+		defer conn.Close()
+		db = conn
+	}
+	defer func() {
+		if tErr := teardownSession(db, teardown); tErr != nil {
+			if conn != nil {
+				// The session can't be restored, so the connection isn't reused
+				conn.Raw(func(interface{}) error { return driver.ErrBadConn })
+			}
+			if err == nil {
+				err = tErr
+			}
+		}
+	}()
+	for _, str := range setup {
+		if _, err := db.ExecContext(ctx, str); err != nil {
+			return err
+		}
+	}
+	return fn(db)
+}
+
+// teardownSession runs the teardown statements on db, returning the errors found.
+func teardownSession(db Execer, teardown []string) error {
+	// The statements run even if the context of the load is done
+	ctx := context.Background()
+	var errs []error
+	for _, str := range teardown {
+		if _, err := db.ExecContext(ctx, str); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
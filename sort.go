@@ -0,0 +1,123 @@
+package bulk
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"sort"
+	"strings"
+	"time"
+)
+
+// SetSortBy makes Insert and Exec sort the buffered rows by the values of columns, usually the primary or
+// unique key, before splitting them into batches. When several loaders write to the same table at the
+// same time, inserting the keys in the same order reduces the deadlocks and the gap lock contention.
+// The numbers, strings, times and bytes are compared by their value, and NULLs sort first. Calling it
+// with no columns disables the sorting.
+func (b *Bulk) SetSortBy(columns ...string) {
+	b.sortColumns = columns
+}
+
+// sortRows sorts the buffered rows as set with SetSortBy, keeping the order of the rows with the same key.
+func (b *Bulk) sortRows() error {
+	if len(b.sortColumns) == 0 || b.rows < 2 {
+		return nil
+	}
+	keyIndex, err := b.columnIndexes(b.sortColumns)
+	if err != nil {
+		return err
+	}
+	order := make([]int, b.rows)
+	keys := make([][]interface{}, b.rows)
+	for r := range order {
+		order[r] = r
+		keys[r] = make([]interface{}, len(keyIndex))
+		for i, c := range keyIndex {
+			keys[r][i] = sortValue(b.vals[r*b.valuesPerRow+c])
+		}
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		a, c := keys[order[i]], keys[order[j]]
+		for k := range a {
+			if n := compareValues(a[k], c[k]); n != 0 {
+				return n < 0
+			}
+		}
+		return false
+	})
+
+	vals := make([]interface{}, len(b.vals), cap(b.vals))
+	for i, r := range order {
+		copy(vals[i*b.valuesPerRow:(i+1)*b.valuesPerRow], b.vals[r*b.valuesPerRow:(r+1)*b.valuesPerRow])
+	}
+	b.vals = vals
+	return nil
+}
+
+// sortValue returns v converted to a driver value, so the values of different Go types can be compared.
+func sortValue(v interface{}) interface{} {
+	dv, err := driver.DefaultParameterConverter.ConvertValue(v)
+	if err != nil {
+		return valueText(v)
+	}
+	return dv
+}
+
+// compareValues returns -1, 0 or 1 as a is less than, equal to or greater than b, which are driver values.
+// The values of different types are compared by their text.
+func compareValues(a, b interface{}) int {
+	if a == nil || b == nil {
+		switch {
+		case a == nil && b == nil:
+			return 0
+		case a == nil:
+			return -1
+		}
+		return 1
+	}
+	switch a := a.(type) {
+	case int64:
+		switch b := b.(type) {
+		case int64:
+			switch {
+			case a < b:
+				return -1
+			case a > b:
+				return 1
+			}
+			return 0
+		case float64:
+			return compareFloats(float64(a), b)
+		}
+	case float64:
+		switch b := b.(type) {
+		case int64:
+			return compareFloats(a, float64(b))
+		case float64:
+			return compareFloats(a, b)
+		}
+	case string:
+		if b, ok := b.(string); ok {
+			return strings.Compare(a, b)
+		}
+	case []byte:
+		if b, ok := b.([]byte); ok {
+			return bytes.Compare(a, b)
+		}
+	case time.Time:
+		if b, ok := b.(time.Time); ok {
+			return a.Compare(b)
+		}
+	}
+	return strings.Compare(valueText(a), valueText(b))
+}
+
+// compareFloats returns -1, 0 or 1 as a is less than, equal to or greater than b.
+func compareFloats(a, b float64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,99 @@
+package bulk
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+)
+
+// SpannerOp is the kind of a SpannerMutation.
+type SpannerOp int
+
+const (
+	SpannerInsert         SpannerOp = iota // The row is inserted, failing if it already exists
+	SpannerInsertOrUpdate                  // The row is inserted, or its columns are updated if it already exists
+	SpannerReplace                         // The row is inserted, or replaced if it already exists
+)
+
+// SpannerMaxMutations is the number of mutations Spanner accepts in a single commit. Each value of a row
+// counts as a mutation, and so does each secondary index it's written to.
+const SpannerMaxMutations = 20000
+
+// SpannerMutation is the mutation writing a row of a table, to be converted to a *spanner.Mutation, e.g.
+// with spanner.InsertOrUpdate(m.Table, m.Columns, m.Values).
+type SpannerMutation struct {
+	Op      SpannerOp
+	Table   string        // Name of the table, as given to Init
+	Columns []string      // Names of the columns, as given to Init
+	Values  []interface{} // Values of the row, sharing the memory of the buffer
+}
+
+// SpannerApplier applies the mutations in a single commit, usually with the Apply method of a
+// *spanner.Client. It isn't implemented here, so this package doesn't depend on the Spanner client.
+type SpannerApplier interface {
+	Apply(ctx context.Context, mutations []SpannerMutation) error
+}
+
+// SpannerMutations returns a Backend which writes the rows to Spanner with mutations applied by a, so the
+// same Bulk can load MySQL or Spanner: a plain insert uses Insert mutations, replaceOnDuplicate uses
+// InsertOrUpdate ones and SetReplaceInto Replace ones. The rows are applied in several commits, each
+// with at most maxMutations mutations, SpannerMaxMutations if it's 0. If a commit fails, the previous ones
+// are kept, and Result.Committed tells the row from which the load can be resumed. The db given to Insert isn't used, so it can be nil, and SetAtomic, SetCommitEvery and
+// SetTruncate aren't supported. The duplicates can't be ignored nor updated with expressions or a subset
+// of the columns, as InsertOrUpdate overwrites every column.
+func SpannerMutations(a SpannerApplier, maxMutations int) Backend {
+	if maxMutations <= 0 {
+		maxMutations = SpannerMaxMutations
+	}
+	return &spannerMutations{applier: a, max: maxMutations}
+}
+
+// spannerMutations is the Backend of SpannerMutations.
+type spannerMutations struct {
+	applier SpannerApplier
+	max     int
+}
+
+// Load applies the rows of t, as many as fit in a commit at a time.
+func (s *spannerMutations) Load(ctx context.Context, _ Execer, t *Table) (sql.Result, error) {
+	op, err := spannerOp(t)
+	if err != nil {
+		return nil, err
+	}
+	perCommit := s.max / len(t.Columns)
+	if perCommit == 0 {
+		return nil, fmt.Errorf("ERROR: A row of %v columns exceeds the limit of %v mutations", len(t.Columns), s.max)
+	}
+	mutations := make([]SpannerMutation, 0, perCommit)
+	var applied int64
+	for start := 0; start < len(t.Rows); start += perCommit {
+		end := start + perCommit
+		if end > len(t.Rows) {
+			end = len(t.Rows)
+		}
+		mutations = mutations[:0]
+		for _, row := range t.Rows[start:end] {
+			mutations = append(mutations, SpannerMutation{Op: op, Table: t.Name, Columns: t.Columns, Values: row})
+		}
+		if err := s.applier.Apply(ctx, mutations); err != nil {
+			return &committedResult{Result: driver.RowsAffected(applied), rows: int(applied)}, err
+		}
+		applied += int64(end - start)
+	}
+	return driver.RowsAffected(applied), nil
+}
+
+// spannerOp returns the kind of the mutations writing the rows of t as its Conflict describes.
+func spannerOp(t *Table) (SpannerOp, error) {
+	c := t.Conflict
+	switch {
+	case c == nil:
+		return SpannerInsert, nil
+	case c.Action == DoReplace:
+		return SpannerReplace, nil
+	case c.Action == DoUpdate && len(c.Set) == 0 && c.UpdateWhere == "" && len(c.Update) == len(t.Columns):
+		return SpannerInsertOrUpdate, nil
+	}
+	return 0, fmt.Errorf("ERROR: Spanner mutations can only insert, replace or overwrite every column of the rows")
+}
@@ -0,0 +1,95 @@
+package bulk
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// mutationsApplier is a SpannerApplier recording the mutations of every commit. The commit number fail
+// fails, counting from 1.
+type mutationsApplier struct {
+	commits [][]SpannerMutation
+	fail    int
+}
+
+func (a *mutationsApplier) Apply(ctx context.Context, mutations []SpannerMutation) error {
+	if len(a.commits)+1 == a.fail {
+		return errors.New("aborted")
+	}
+	a.commits = append(a.commits, append([]SpannerMutation(nil), mutations...))
+	return nil
+}
+
+func TestSpannerMutations(t *testing.T) {
+	tests := []struct {
+		name    string
+		replace bool
+		into    bool
+		ignore  bool
+		want    SpannerOp
+		wantErr string
+	}{
+		{name: "insert", want: SpannerInsert},
+		{name: "upsert", replace: true, want: SpannerInsertOrUpdate},
+		{name: "replace into", into: true, want: SpannerReplace},
+		{name: "ignore", ignore: true, wantErr: "Spanner mutations can only insert, replace or overwrite every column"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := &mutationsApplier{}
+			b := New(MySQL)
+			b.Init("t", "id", "name")
+			b.SetBackend(SpannerMutations(a, 4))
+			b.SetReplaceInto(tt.into)
+			b.SetIgnoreDuplicates(tt.ignore)
+			for i := 1; i <= 3; i++ {
+				b.PrepareValues(i, "n")
+			}
+			result, err := b.Exec(context.Background(), nil, tt.replace)
+			if !checkError(t, err, tt.wantErr) {
+				return
+			}
+			// Two rows of two columns fit in a commit of 4 mutations
+			want := [][]SpannerMutation{
+				{
+					{Op: tt.want, Table: "t", Columns: []string{"id", "name"}, Values: []interface{}{1, "n"}},
+					{Op: tt.want, Table: "t", Columns: []string{"id", "name"}, Values: []interface{}{2, "n"}},
+				},
+				{{Op: tt.want, Table: "t", Columns: []string{"id", "name"}, Values: []interface{}{3, "n"}}},
+			}
+			if !reflect.DeepEqual(a.commits, want) {
+				t.Errorf("got %v, expected %v", a.commits, want)
+			}
+			if result.RowsAffected != 3 || result.Committed != 3 {
+				t.Errorf("got %+v", result)
+			}
+		})
+	}
+}
+
+func TestSpannerMutationsFailure(t *testing.T) {
+	a := &mutationsApplier{fail: 2}
+	b := New(MySQL)
+	b.Init("t", "id", "name")
+	b.SetBackend(SpannerMutations(a, 4))
+	for i := 1; i <= 3; i++ {
+		b.PrepareValues(i, "n")
+	}
+	// The rows of the first commit are kept
+	result, err := b.Exec(context.Background(), nil, false)
+	if err == nil {
+		t.Fatal("expected the error of the second commit")
+	}
+	if result.Committed != 2 || result.RowsAffected != 2 {
+		t.Errorf("got %+v, expected the 2 rows of the first commit", result)
+	}
+
+	b = New(MySQL)
+	b.Init("t", "id", "name", "a", "b", "c")
+	b.SetBackend(SpannerMutations(a, 4))
+	b.PrepareValues(1, 2, 3, 4, 5)
+	_, err = b.Exec(context.Background(), nil, false)
+	checkError(t, err, "A row of 5 columns exceeds the limit of 4 mutations")
+}
@@ -0,0 +1,150 @@
+package bulk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// missing is the type of Missing.
+type missing struct{}
+
+// Missing is a value given for the columns a row doesn't provide, e.g. b.PrepareValues(1, bulk.Missing,
+// "x"), or as the default of SetMapDefaults for the maps which don't have all the columns. The rows are
+// grouped by the columns they provide, in the order of their first row, and each group is inserted with
+// its own statements, which leave the missing columns out: they get their default value, or NULL if they
+// have none, and aren't overwritten on duplicate. The backends set with SetBackend don't support it.
+//
+// The rows of a group are inserted together, so Result.Committed only advances once every group is
+// inserted, and the FirstRow of a BatchError is the index in the buffer of the first row of the batch.
+// With SetAtomic or SetTruncate, every group runs inside the same transaction.
+var Missing interface{} = missing{}
+
+// sparseGroup is a group of rows which provide the same columns.
+type sparseGroup struct {
+	columns []int // Indexes of the provided columns
+	rows    []int // Indexes of the rows in the buffer
+}
+
+// sparseGroups groups the rows to insert by the columns they provide.
+func (b *Bulk) sparseGroups() []*sparseGroup {
+	var groups []*sparseGroup
+	index := map[string]*sparseGroup{}
+	key := make([]byte, b.valuesPerRow)
+	for r := b.resumeRow; r < b.rows; r++ {
+		row := b.vals[r*b.valuesPerRow : (r+1)*b.valuesPerRow]
+		for i, v := range row {
+			key[i] = 1
+			if v == Missing {
+				key[i] = 0
+			}
+		}
+		g, ok := index[string(key)]
+		if !ok {
+			g = &sparseGroup{}
+			for i, v := range row {
+				if v != Missing {
+					g.columns = append(g.columns, i)
+				}
+			}
+			index[string(key)] = g
+			groups = append(groups, g)
+		}
+		g.rows = append(g.rows, r)
+	}
+	return groups
+}
+
+// sparseBulk returns a copy of the Bulk with the columns and the rows of g only.
+func (b *Bulk) sparseBulk(g *sparseGroup, first bool) (*Bulk, error) {
+	if len(g.columns) == 0 {
+		return nil, fmt.Errorf("ERROR: The row %v provides no column", g.rows[0])
+	}
+	c := *b
+	c.columns = make([]string, len(g.columns))
+	c.appended = 0
+	for i, col := range g.columns {
+		c.columns[i] = b.columns[col]
+		if col >= len(b.columns)-b.appended {
+			c.appended++
+		}
+	}
+	c.valuesPerRow = len(g.columns)
+	c.vals = make([]interface{}, 0, len(g.rows)*len(g.columns))
+	for _, r := range g.rows {
+		row := b.vals[r*b.valuesPerRow : (r+1)*b.valuesPerRow]
+		for _, col := range g.columns {
+			c.vals = append(c.vals, row[col])
+		}
+	}
+	c.rows, c.resumeRow, c.sparse = len(g.rows), 0, false
+	c.truncate = b.truncate && first
+	c.dedupeColumns, c.sortColumns, c.checkpoint = nil, nil, nil
+	c.stats = &statsRecorder{}
+	c.columnEncoders, c.columnNullIfZero, c.columnValidators, c.columnExprs = nil, nil, nil, nil
+	c.columnUUIDs, c.columnTimeZones, c.columnGuards = nil, nil, nil
+	if len(b.updateColumns) > 0 {
+		c.updateColumns = nil
+		for _, u := range b.updateColumns {
+			if contains(c.columns, u) {
+				c.updateColumns = append(c.updateColumns, u)
+			}
+		}
+		if len(c.updateColumns) == 0 {
+			return nil, fmt.Errorf("ERROR: The row %v provides none of the columns updated on duplicate", g.rows[0])
+		}
+	}
+	return &c, nil
+}
+
+// execSparse inserts the rows with Missing values, group by group.
+func (b *Bulk) execSparse(ctx context.Context, stmts *stmtCache, replaceOnDuplicate bool, result *Result) error {
+	if b.backend != nil {
+		return fmt.Errorf("ERROR: The Missing values can't be loaded with a backend")
+	}
+	groups := b.sparseGroups()
+	run := func(stmts *stmtCache) error {
+		for i, g := range groups {
+			sub, err := b.sparseBulk(g, i == 0)
+			if err != nil {
+				return err
+			}
+			res, err := sub.execLoad(ctx, stmts, replaceOnDuplicate)
+			for _, e := range res.RowErrors {
+				result.RowErrors = append(result.RowErrors, &RowError{Row: g.rows[e.Row], Values: e.Values, Err: e.Err})
+			}
+			result.RowsAffected += res.RowsAffected
+			result.Batches += res.Batches
+			result.Results = append(result.Results, res.Results...)
+			b.stats.merge(sub.Stats())
+			if err != nil {
+				var be *BatchError
+				if errors.As(err, &be) && be.FirstRow < len(g.rows) {
+					be.FirstRow = g.rows[be.FirstRow]
+				}
+				return err
+			}
+		}
+		return nil
+	}
+	if !b.singleTx() {
+		if err := run(stmts); err != nil {
+			return err
+		}
+	} else {
+		saved := *result
+		err := b.withTx(ctx, stmts.db, func(tx Execer) error {
+			// A restarted transaction inserts every group again
+			*result = saved
+			txStmts := newStmtCache(tx)
+			defer txStmts.close()
+			return run(txStmts)
+		})
+		if err != nil {
+			*result = saved
+			return err
+		}
+	}
+	result.Committed = b.rows
+	return nil
+}
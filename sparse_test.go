@@ -0,0 +1,95 @@
+package bulk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestMissing(t *testing.T) {
+	r := NewRecorder()
+	b := New(Postgres)
+	b.Init("t", "id", "name", "n")
+	b.SetConflictTarget("id")
+	b.PrepareValues(1, "a", Missing)
+	b.PrepareValues(2, Missing, Missing)
+	b.PrepareValues(3, "c", Missing)
+	b.PrepareValues(4, "d", 5)
+	result, err := b.Exec(context.Background(), r.DB(), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The groups are inserted in the order of their first row, without their missing columns
+	err = r.Expect(
+		Statement{SQL: `INSERT INTO "t"("id", "name") VALUES ($1,$2),($3,$4) ON CONFLICT ("id") DO UPDATE SET "id"=EXCLUDED."id","name"=EXCLUDED."name"`, Args: []interface{}{1, "a", 3, "c"}},
+		Statement{SQL: `INSERT INTO "t"("id") VALUES ($1) ON CONFLICT ("id") DO UPDATE SET "id"=EXCLUDED."id"`, Args: []interface{}{2}},
+		Statement{SQL: `INSERT INTO "t"("id", "name", "n") VALUES ($1,$2,$3) ON CONFLICT ("id") DO UPDATE SET "id"=EXCLUDED."id","name"=EXCLUDED."name","n"=EXCLUDED."n"`, Args: []interface{}{4, "d", 5}},
+	)
+	if err != nil {
+		t.Error(err)
+	}
+	if result.Batches != 3 || result.Committed != 4 {
+		t.Errorf("got %+v", result)
+	}
+}
+
+func TestMissingFailure(t *testing.T) {
+	r := NewRecorder()
+	r.SetResponder(func(s Statement) (int64, error) {
+		if s.Args[0] == 4 {
+			return 0, fmt.Errorf("boom")
+		}
+		return 1, nil
+	})
+	b := New(MySQL)
+	b.Init("t", "id", "name")
+	b.SetBatchLimit(2)
+	b.PrepareValues(1, Missing)
+	b.PrepareValues(2, "b")
+	b.PrepareValues(3, Missing)
+	b.PrepareValues(4, "d")
+	result, err := b.Exec(context.Background(), r.DB(), false)
+	// The first row of the failed batch is given by its index in the buffer
+	var be *BatchError
+	if !errors.As(err, &be) || be.FirstRow != 3 {
+		t.Fatalf("got the error %v, expected the batch of the row 3", err)
+	}
+	if result.Committed != 0 {
+		t.Errorf("got %v committed rows, expected none until every group is inserted", result.Committed)
+	}
+}
+
+func TestMissingErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		setup   func(b *Bulk)
+		replace bool
+		wantErr string
+	}{
+		{name: "no column", setup: func(b *Bulk) { b.PrepareValues(Missing, Missing) }, wantErr: "The row 1 provides no column"},
+		{
+			name: "no updated column",
+			setup: func(b *Bulk) {
+				b.OnDuplicateUpdate("name")
+				b.PrepareValues(2, Missing)
+			},
+			replace: true,
+			wantErr: "The row 1 provides none of the columns updated on duplicate",
+		},
+		{name: "backend", setup: func(b *Bulk) { b.SetBackend(OracleArrayBind) }, wantErr: "The Missing values can't be loaded with a backend"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := New(MySQL)
+			b.Init("t", "id", "name")
+			b.PrepareValues(1, "a")
+			tt.setup(b)
+			if b.rows == 1 {
+				b.PrepareValues(2, Missing)
+			}
+			_, err := b.Exec(context.Background(), NewRecorder().DB(), tt.replace)
+			checkError(t, err, tt.wantErr)
+		})
+	}
+}
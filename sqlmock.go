@@ -0,0 +1,102 @@
+package bulk
+
+import (
+	"database/sql/driver"
+	"regexp"
+)
+
+// MockKind is the kind of a MockExpectation.
+type MockKind int
+
+const (
+	MockExec   MockKind = iota // A statement is executed, as expected by ExpectExec
+	MockBegin                  // A transaction is started, as expected by ExpectBegin
+	MockCommit                 // The transaction is committed, as expected by ExpectCommit
+)
+
+// MockExpectation is a call Insert makes on its db, in the form taken by github.com/DATA-DOG/go-sqlmock.
+type MockExpectation struct {
+	Kind    MockKind
+	SQL     string         // Exact SQL of the statement, for sqlmock.QueryMatcherEqual
+	Pattern string         // SQL quoted as a regular expression, for the default sqlmock.QueryMatcherRegexp
+	Args    []driver.Value // Arguments of the statement, converted as given to WithArgs
+	Rows    int            // Number of rows inserted by the statement
+	Prepare bool           // The statement is prepared before it's executed, instead of reusing a previous one
+}
+
+// MockExpectations returns the calls which Insert with replaceOnDuplicate would make on its db for the
+// buffered rows, in order, so the tests using go-sqlmock can build their expectations from them instead of
+// copying the generated SQL, e.g.:
+//
+//	for _, e := range expectations {
+//		switch e.Kind {
+//		case bulk.MockBegin:
+//			mock.ExpectBegin()
+//		case bulk.MockCommit:
+//			mock.ExpectCommit()
+//		case bulk.MockExec:
+//			if e.Prepare {
+//				mock.ExpectPrepare(e.Pattern)
+//			}
+//			mock.ExpectExec(e.Pattern).WithArgs(e.Args...).WillReturnResult(sqlmock.NewResult(0, int64(e.Rows)))
+//		}
+//	}
+//
+// The statements of SetTruncate, SetSession, SetDisableKeys and SetIndexDDL aren't included, nor are the
+// retries, and the rows aren't deduplicated nor sorted, so those options shouldn't be used with it. The
+// transactions of the dialects implementing TxRestarter set and release their savepoint, as statements
+// without arguments nor rows.
+func (b *Bulk) MockExpectations(replaceOnDuplicate bool) ([]MockExpectation, error) {
+	head, endStr, batches, err := b.plan(replaceOnDuplicate)
+	if err != nil {
+		return nil, err
+	}
+	var expectations []MockExpectation
+	// The transactions of a TxRestarter dialect are wrapped in its savepoint
+	var savepoint string
+	if r, ok := b.dialect.(TxRestarter); ok {
+		savepoint = r.RestartSavepoint()
+	}
+	prepared := map[string]bool{}
+	for _, group := range b.txGroups(batches) {
+		if b.inTx() {
+			// Every transaction prepares its own statements
+			expectations = append(expectations, MockExpectation{Kind: MockBegin})
+			if savepoint != "" {
+				expectations = append(expectations, mockStatement("SAVEPOINT "+savepoint))
+			}
+			prepared = map[string]bool{}
+		}
+		for _, bt := range group {
+			str, args, err := b.batchStatement(head, bt, endStr)
+			if err != nil {
+				return nil, err
+			}
+			e := MockExpectation{Kind: MockExec, SQL: str, Pattern: regexp.QuoteMeta(str), Rows: bt.rows()}
+			for _, a := range args {
+				v, err := driver.DefaultParameterConverter.ConvertValue(a)
+				if err != nil {
+					return nil, err
+				}
+				e.Args = append(e.Args, v)
+			}
+			if !b.interpolate && !prepared[str] {
+				e.Prepare = true
+				prepared[str] = true
+			}
+			expectations = append(expectations, e)
+		}
+		if b.inTx() {
+			if savepoint != "" {
+				expectations = append(expectations, mockStatement("RELEASE SAVEPOINT "+savepoint))
+			}
+			expectations = append(expectations, MockExpectation{Kind: MockCommit})
+		}
+	}
+	return expectations, nil
+}
+
+// mockStatement returns the expectation of the statement str, executed without arguments nor preparing it.
+func mockStatement(str string) MockExpectation {
+	return MockExpectation{Kind: MockExec, SQL: str, Pattern: regexp.QuoteMeta(str)}
+}
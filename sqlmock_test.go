@@ -0,0 +1,67 @@
+package bulk
+
+import (
+	"database/sql/driver"
+	"reflect"
+	"testing"
+)
+
+func TestMockExpectations(t *testing.T) {
+	tests := []struct {
+		name    string
+		dialect Dialect
+		atomic  bool
+		want    []MockExpectation
+	}{
+		{
+			name:    "batches",
+			dialect: Postgres,
+			want: []MockExpectation{
+				{Kind: MockExec, SQL: `INSERT INTO "t"("id") VALUES ($1),($2)`, Pattern: `INSERT INTO "t"\("id"\) VALUES \(\$1\),\(\$2\)`, Args: []driver.Value{int64(1), int64(2)}, Rows: 2, Prepare: true},
+				{Kind: MockExec, SQL: `INSERT INTO "t"("id") VALUES ($1)`, Pattern: `INSERT INTO "t"\("id"\) VALUES \(\$1\)`, Args: []driver.Value{int64(3)}, Rows: 1, Prepare: true},
+			},
+		},
+		{
+			name:    "transaction",
+			dialect: Postgres,
+			atomic:  true,
+			want: []MockExpectation{
+				{Kind: MockBegin},
+				{Kind: MockExec, SQL: `INSERT INTO "t"("id") VALUES ($1),($2)`, Pattern: `INSERT INTO "t"\("id"\) VALUES \(\$1\),\(\$2\)`, Args: []driver.Value{int64(1), int64(2)}, Rows: 2, Prepare: true},
+				{Kind: MockExec, SQL: `INSERT INTO "t"("id") VALUES ($1)`, Pattern: `INSERT INTO "t"\("id"\) VALUES \(\$1\)`, Args: []driver.Value{int64(3)}, Rows: 1, Prepare: true},
+				{Kind: MockCommit},
+			},
+		},
+		{
+			name:    "restart savepoint",
+			dialect: CockroachDB,
+			atomic:  true,
+			want: []MockExpectation{
+				{Kind: MockBegin},
+				{Kind: MockExec, SQL: "SAVEPOINT cockroach_restart", Pattern: "SAVEPOINT cockroach_restart"},
+				{Kind: MockExec, SQL: `INSERT INTO "t"("id") VALUES ($1),($2)`, Pattern: `INSERT INTO "t"\("id"\) VALUES \(\$1\),\(\$2\)`, Args: []driver.Value{int64(1), int64(2)}, Rows: 2, Prepare: true},
+				{Kind: MockExec, SQL: `INSERT INTO "t"("id") VALUES ($1)`, Pattern: `INSERT INTO "t"\("id"\) VALUES \(\$1\)`, Args: []driver.Value{int64(3)}, Rows: 1, Prepare: true},
+				{Kind: MockExec, SQL: "RELEASE SAVEPOINT cockroach_restart", Pattern: "RELEASE SAVEPOINT cockroach_restart"},
+				{Kind: MockCommit},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := New(tt.dialect)
+			b.Init("t", "id")
+			b.SetBatchLimit(2)
+			b.SetAtomic(tt.atomic)
+			for i := 1; i <= 3; i++ {
+				b.PrepareValues(i)
+			}
+			got, err := b.MockExpectations(false)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %+v, expected %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,126 @@
+package bulk
+
+import (
+	"database/sql"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// AddNamed appends a row per value of arg, binding it with named parameters as sqlx does, so the structs
+// used with github.com/jmoiron/sqlx can be loaded as is. names lists the parameters of the columns given
+// to Init, in the same order, e.g. ":name, :age". arg is a struct, a pointer to a struct or a
+// map[string]interface{}, or a slice of them. The struct fields are matched by their `db` tag, or by their
+// lowercase name if they have none, as the default mapper of sqlx does. The rows are appended with
+// AddRows, so if a value can't be bound or a row is invalid, no row is appended.
+func (b *Bulk) AddNamed(names string, arg interface{}) error {
+	params, err := parseNamed(names)
+	if err != nil {
+		return err
+	}
+	if len(params) != len(b.inputColumns()) {
+		return fmt.Errorf("ERROR: AddNamed got %v parameters for %v columns", len(params), len(b.inputColumns()))
+	}
+	v := reflect.ValueOf(arg)
+	elems := []reflect.Value{v}
+	if v.Kind() == reflect.Slice || v.Kind() == reflect.Array {
+		elems = make([]reflect.Value, v.Len())
+		for i := range elems {
+			elems[i] = v.Index(i)
+		}
+	}
+
+	fields := map[reflect.Type][][]int{}
+	rows := make([][]interface{}, len(elems))
+	for i, elem := range elems {
+		if rows[i], err = namedRow(elem, params, fields); err != nil {
+			return err
+		}
+	}
+	return b.AddRows(rows)
+}
+
+// parseNamed returns the names of the comma separated named parameters of names, without their colon.
+func parseNamed(names string) ([]string, error) {
+	var params []string
+	for _, p := range strings.Split(names, ",") {
+		p = strings.TrimSpace(p)
+		if len(p) < 2 || p[0] != ':' {
+			return nil, fmt.Errorf("ERROR: Invalid named parameter %q", p)
+		}
+		params = append(params, p[1:])
+	}
+	return params, nil
+}
+
+// namedRow returns the values of params held by v, caching in fields the indexes of the fields of each
+// struct type.
+func namedRow(v reflect.Value, params []string, fields map[reflect.Type][][]int) ([]interface{}, error) {
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return nil, fmt.Errorf("ERROR: AddNamed can't bind a nil value")
+		}
+		v = v.Elem()
+	}
+	if !v.IsValid() {
+		return nil, fmt.Errorf("ERROR: AddNamed can't bind a nil value")
+	}
+	vals := make([]interface{}, len(params))
+	switch v.Kind() {
+	case reflect.Map:
+		m, ok := v.Interface().(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("ERROR: AddNamed requires a map[string]interface{}, got %v", v.Type())
+		}
+		for i, p := range params {
+			val, ok := m[p]
+			if !ok {
+				return nil, fmt.Errorf("ERROR: The map has no value for the parameter :%v", p)
+			}
+			vals[i] = val
+		}
+	case reflect.Struct:
+		index, ok := fields[v.Type()]
+		if !ok {
+			tagged := map[string][]int{}
+			named := map[string][]int{}
+			collectFields(v.Type(), "db", nil, tagged, named)
+			index = make([][]int, len(params))
+			for i, p := range params {
+				if index[i], ok = tagged[p]; !ok {
+					if index[i], ok = named[strings.ToLower(p)]; !ok {
+						return nil, fmt.Errorf("ERROR: The struct %v has no field for the parameter :%v", v.Type(), p)
+					}
+				}
+			}
+			fields[v.Type()] = index
+		}
+		for i := range params {
+			vals[i] = v.FieldByIndex(index[i]).Interface()
+		}
+	default:
+		return nil, fmt.Errorf("ERROR: AddNamed can't bind a value of type %v", v.Type())
+	}
+	return vals, nil
+}
+
+// sqlTx returns the *sql.Tx embedded by db, such as the one of a *sqlx.Tx, so it's handled as a
+// transaction of the caller. Otherwise, it returns db. The *sqlx.DB and *sqlx.Conn handles embed the
+// *sql.DB and *sql.Conn ones, whose methods they promote, so they need no conversion.
+func sqlTx(db Execer) Execer {
+	if _, ok := db.(*sql.Tx); ok {
+		return db
+	}
+	v := reflect.ValueOf(db)
+	if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return db
+	}
+	f, ok := v.Elem().Type().FieldByName("Tx")
+	if !ok || !f.Anonymous || len(f.Index) != 1 || f.Type != reflect.TypeOf((*sql.Tx)(nil)) {
+		return db
+	}
+	if tx := v.Elem().FieldByIndex(f.Index); !tx.IsNil() {
+		return tx.Interface().(*sql.Tx)
+	}
+	return db
+}
@@ -0,0 +1,53 @@
+package bulk
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestAddNamed(t *testing.T) {
+	type person struct {
+		ID   int `db:"id"`
+		Name string
+	}
+	tests := []struct {
+		name     string
+		names    string
+		arg      interface{}
+		wantArgs []interface{}
+		wantErr  string
+	}{
+		{name: "struct", names: ":id, :name", arg: person{1, "a"}, wantArgs: []interface{}{1, "a"}},
+		{name: "pointers", names: ":id, :name", arg: []*person{{1, "a"}, {2, "b"}}, wantArgs: []interface{}{1, "a", 2, "b"}},
+		{name: "map", names: ":id, :name", arg: map[string]interface{}{"id": 1, "name": "a"}, wantArgs: []interface{}{1, "a"}},
+		{name: "maps", names: ":id, :name", arg: []map[string]interface{}{{"id": 1, "name": "a"}, {"id": 2, "name": "b"}}, wantArgs: []interface{}{1, "a", 2, "b"}},
+		{name: "nil", names: ":id, :name", arg: nil, wantErr: "can't bind a nil value"},
+		{name: "nil pointer", names: ":id, :name", arg: []*person{{1, "a"}, nil}, wantErr: "can't bind a nil value"},
+		{name: "nil in slice", names: ":id, :name", arg: []interface{}{person{1, "a"}, nil}, wantErr: "can't bind a nil value"},
+		{name: "missing field", names: ":id, :age", arg: person{1, "a"}, wantErr: "has no field for the parameter :age"},
+		{name: "missing key", names: ":id, :name", arg: map[string]interface{}{"id": 1}, wantErr: "has no value for the parameter :name"},
+		{name: "parameters", names: ":id", arg: person{1, "a"}, wantErr: "got 1 parameters for 2 columns"},
+		{name: "invalid parameter", names: ":id, name", arg: person{1, "a"}, wantErr: `Invalid named parameter "name"`},
+		{name: "type", names: ":id, :name", arg: 1, wantErr: "can't bind a value of type int"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := New(MySQL)
+			b.Init("t", "id", "name")
+			err := b.AddNamed(tt.names, tt.arg)
+			if !checkError(t, err, tt.wantErr) {
+				if b.rows != 0 {
+					t.Errorf("got %v rows, expected none", b.rows)
+				}
+				return
+			}
+			statements, err := b.BuildStatements(false)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(statements[0].Args, tt.wantArgs) {
+				t.Errorf("got %v, expected %v", statements[0].Args, tt.wantArgs)
+			}
+		})
+	}
+}
@@ -0,0 +1,158 @@
+package bulk
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// Uploader stores a file in an object store such as S3, returning the location COPY reads it from, e.g.
+// s3://bucket/key. It isn't implemented here, so this package doesn't depend on the clients of the stores.
+type Uploader interface {
+	Upload(ctx context.Context, name string, r io.Reader) (location string, err error)
+}
+
+// UploadDeleter is implemented by the Uploaders which can delete the files they stored.
+type UploadDeleter interface {
+	Delete(ctx context.Context, location string) error
+}
+
+// StagedCopyOptions configures the RedshiftCopy and SnowflakeCopy backends.
+type StagedCopyOptions struct {
+	Uploader    Uploader // Stores the file of the rows, required
+	Prefix      string   // Prepended to the name of the file, e.g. "loads/"
+	Credentials string   // Authorization clause of COPY, e.g. "IAM_ROLE 'arn:...'" or "STORAGE_INTEGRATION = s3_load"
+	Keep        bool     // Keep the file once loaded, otherwise it's deleted if the Uploader is an UploadDeleter
+}
+
+// fileCount numbers the files uploaded by the staged copies.
+var fileCount int64
+
+// RedshiftCopy returns a Redshift Backend which writes the rows to a gzipped CSV file, uploads it with
+// opts.Uploader and loads it with a single COPY statement, the only practical way to load large amounts of
+// rows into Redshift. Duplicates can't be handled.
+func RedshiftCopy(opts *StagedCopyOptions) Backend {
+	return &stagedCopy{opts: opts, copy: redshiftCopy}
+}
+
+// SnowflakeCopy returns a Snowflake Backend which writes the rows to a gzipped CSV file, uploads it with
+// opts.Uploader and loads it with a single COPY INTO statement. Duplicates can't be handled.
+func SnowflakeCopy(opts *StagedCopyOptions) Backend {
+	return &stagedCopy{opts: opts, copy: snowflakeCopy}
+}
+
+// stagedCopy is the Backend of RedshiftCopy and SnowflakeCopy.
+type stagedCopy struct {
+	opts *StagedCopyOptions
+	copy func(t *Table, location, credentials string) string // Returns the COPY statement of the quoted location
+}
+
+// redshiftCopy returns the statement loading the file at the quoted location into the table of t.
+func redshiftCopy(t *Table, location, credentials string) string {
+	return "COPY " + t.QuotedName + " (" + strings.Join(t.QuotedColumns, ", ") + ") FROM " + location +
+		withSpace(credentials) + ` FORMAT AS CSV GZIP NULL AS '\\N' TIMEFORMAT 'auto'`
+}
+
+// snowflakeCopy returns the statement loading the file at the quoted location into the table of t.
+func snowflakeCopy(t *Table, location, credentials string) string {
+	return "COPY INTO " + t.QuotedName + " (" + strings.Join(t.QuotedColumns, ", ") + ") FROM " + location +
+		withSpace(credentials) + ` FILE_FORMAT = (TYPE = CSV COMPRESSION = GZIP FIELD_OPTIONALLY_ENCLOSED_BY = '"' NULL_IF = ('\\N') EMPTY_FIELD_AS_NULL = FALSE)`
+}
+
+// withSpace returns s preceded by a space, or "" if it's empty.
+func withSpace(s string) string {
+	if s == "" {
+		return ""
+	}
+	return " " + s
+}
+
+// Load uploads the rows of t and copies them into the table.
+func (s *stagedCopy) Load(ctx context.Context, db Execer, t *Table) (sql.Result, error) {
+	if t.Conflict != nil {
+		return nil, fmt.Errorf("ERROR: A staged COPY can't handle duplicates")
+	}
+	if s.opts == nil || s.opts.Uploader == nil {
+		return nil, fmt.Errorf("ERROR: A staged COPY requires an Uploader")
+	}
+	// The file is compressed while it's uploaded
+	pr, pw := io.Pipe()
+	go func() {
+		zw := gzip.NewWriter(pw)
+		err := writeCSV(zw, t.Rows)
+		if cerr := zw.Close(); err == nil {
+			err = cerr
+		}
+		pw.CloseWithError(err)
+	}()
+	name := s.opts.Prefix + "bulk-" + strconv.FormatInt(time.Now().UnixNano(), 36) + "-" +
+		strconv.FormatInt(atomic.AddInt64(&fileCount, 1), 10) + ".csv.gz"
+	location, err := s.opts.Uploader.Upload(ctx, name, pr)
+	pr.CloseWithError(io.ErrClosedPipe)
+	if err != nil {
+		return nil, err
+	}
+	quoted, err := quoteStandard(location)
+	if err != nil {
+		return nil, fmt.Errorf("ERROR: Invalid location %q: %v", location, err)
+	}
+	if d, ok := s.opts.Uploader.(UploadDeleter); ok && !s.opts.Keep {
+		defer d.Delete(ctx, location)
+	}
+	return db.ExecContext(ctx, s.copy(t, quoted, s.opts.Credentials))
+}
+
+// csvQuoter doubles the quotes of a quoted CSV field.
+var csvQuoter = strings.NewReplacer(`"`, `""`)
+
+// writeCSV writes rows to w as CSV lines, with the strings always quoted, so an empty string isn't read as
+// NULL, which is written as \N. The binary values are written in hexadecimal.
+func writeCSV(w io.Writer, rows [][]interface{}) error {
+	var line bytes.Buffer
+	for _, row := range rows {
+		line.Reset()
+		for i, v := range row {
+			if i > 0 {
+				line.WriteByte(',')
+			}
+			v, err := driver.DefaultParameterConverter.ConvertValue(v)
+			if err != nil {
+				return err
+			}
+			switch v := v.(type) {
+			case nil:
+				line.WriteString(`\N`)
+			case int64:
+				line.WriteString(strconv.FormatInt(v, 10))
+			case float64:
+				line.WriteString(strconv.FormatFloat(v, 'g', -1, 64))
+			case bool:
+				line.WriteString(strconv.FormatBool(v))
+			case []byte:
+				line.WriteString(hex.EncodeToString(v))
+			case string:
+				line.WriteByte('"')
+				csvQuoter.WriteString(&line, v)
+				line.WriteByte('"')
+			case time.Time:
+				line.WriteString(v.Format("2006-01-02 15:04:05.999999Z07:00"))
+			default:
+				return fmt.Errorf("ERROR: A staged COPY can't write the value %v of type %T", v, v)
+			}
+		}
+		line.WriteByte('\n')
+		if _, err := w.Write(line.Bytes()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,108 @@
+package bulk
+
+import (
+	"compress/gzip"
+	"context"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+// memoryUploader is an UploadDeleter keeping the uncompressed files in memory.
+type memoryUploader struct {
+	files   map[string]string
+	deleted []string
+}
+
+func (u *memoryUploader) Upload(ctx context.Context, name string, r io.Reader) (string, error) {
+	zr, err := gzip.NewReader(r)
+	if err != nil {
+		return "", err
+	}
+	data, err := io.ReadAll(zr)
+	if err != nil {
+		return "", err
+	}
+	location := "s3://bucket/" + name
+	u.files[location] = string(data)
+	return location, nil
+}
+
+func (u *memoryUploader) Delete(ctx context.Context, location string) error {
+	u.deleted = append(u.deleted, location)
+	return nil
+}
+
+func TestStagedCopy(t *testing.T) {
+	tests := []struct {
+		name    string
+		backend func(opts *StagedCopyOptions) Backend
+		want    string // Statement, with %s for the location
+	}{
+		{
+			name:    "redshift",
+			backend: RedshiftCopy,
+			want:    `COPY "t" ("id", "name", "data", "at") FROM '%s' IAM_ROLE 'arn' FORMAT AS CSV GZIP NULL AS '\\N' TIMEFORMAT 'auto'`,
+		},
+		{
+			name:    "snowflake",
+			backend: SnowflakeCopy,
+			want:    `COPY INTO "t" ("id", "name", "data", "at") FROM '%s' IAM_ROLE 'arn' FILE_FORMAT = (TYPE = CSV COMPRESSION = GZIP FIELD_OPTIONALLY_ENCLOSED_BY = '"' NULL_IF = ('\\N') EMPTY_FIELD_AS_NULL = FALSE)`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u := &memoryUploader{files: map[string]string{}}
+			r := NewRecorder()
+			b := New(Postgres)
+			b.Init("t", "id", "name", "data", "at")
+			b.SetBackend(tt.backend(&StagedCopyOptions{Uploader: u, Prefix: "loads/", Credentials: "IAM_ROLE 'arn'"}))
+			b.PrepareValues(1, `a "b"`, []byte{1, 255}, time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC))
+			b.PrepareValues(2.5, "", nil, true)
+			if err := b.Insert(r.DB(), false); err != nil {
+				t.Fatal(err)
+			}
+			if len(u.files) != 1 {
+				t.Fatalf("got %v files, expected 1", len(u.files))
+			}
+			for location, data := range u.files {
+				if !strings.HasPrefix(location, "s3://bucket/loads/bulk-") {
+					t.Errorf("got the location %v", location)
+				}
+				if want := "1,\"a \"\"b\"\"\",01ff,2020-01-02 03:04:05Z\n2.5,\"\",\\N,true\n"; data != want {
+					t.Errorf("got the file %q, expected %q", data, want)
+				}
+				if err := r.ExpectSQL(strings.Replace(tt.want, "%s", location, 1)); err != nil {
+					t.Error(err)
+				}
+				// The file is deleted once loaded
+				if len(u.deleted) != 1 || u.deleted[0] != location {
+					t.Errorf("got the deleted files %v", u.deleted)
+				}
+			}
+		})
+	}
+}
+
+func TestStagedCopyErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    *StagedCopyOptions
+		replace bool
+		wantErr string
+	}{
+		{name: "no uploader", opts: &StagedCopyOptions{}, wantErr: "A staged COPY requires an Uploader"},
+		{name: "duplicates", opts: &StagedCopyOptions{Uploader: &memoryUploader{files: map[string]string{}}}, replace: true, wantErr: "A staged COPY can't handle duplicates"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := New(Postgres)
+			b.Init("t", "id")
+			b.SetConflictTarget("id")
+			b.SetBackend(RedshiftCopy(tt.opts))
+			b.PrepareValues(1)
+			checkError(t, b.Insert(NewRecorder().DB(), tt.replace), tt.wantErr)
+		})
+	}
+}
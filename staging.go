@@ -0,0 +1,115 @@
+package bulk
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Stager is implemented by the dialects which can create temporary staging tables, such as MySQL,
+// Postgres and SQL Server.
+type Stager interface {
+	// StagingName returns the name of the temporary table used to stage the rows of the table name.
+	StagingName(name string) string
+	// CreateStaging returns the statement creating the temporary table staging with the columns of table.
+	CreateStaging(staging, table string) string
+}
+
+func (mysqlDialect) StagingName(name string) string {
+	return name + "_staging"
+}
+
+func (mysqlDialect) CreateStaging(staging, table string) string {
+	return "CREATE TEMPORARY TABLE " + staging + " LIKE " + table
+}
+
+func (postgresDialect) StagingName(name string) string {
+	return name + "_staging"
+}
+
+func (postgresDialect) CreateStaging(staging, table string) string {
+	return "CREATE TEMPORARY TABLE " + staging + " (LIKE " + table + " INCLUDING DEFAULTS)"
+}
+
+func (sqlServerDialect) StagingName(name string) string {
+	return "#" + name + "_staging"
+}
+
+func (sqlServerDialect) CreateStaging(staging, table string) string {
+	return "SELECT TOP 0 * INTO " + staging + " FROM " + table
+}
+
+// dropTimeout is the time given to the statement dropping the staging table.
+const dropTimeout = 10 * time.Second
+
+// connector is implemented by the pools which can pin a connection, such as *sql.DB.
+type connector interface {
+	Conn(ctx context.Context) (*sql.Conn, error)
+}
+
+// StagedUpsert upserts the rows like Exec with replaceOnDuplicate, but in two steps much faster for large
+// loads: the rows are inserted into a temporary staging table, with the backend set with SetBackend if
+// any, and then upserted from it with a single INSERT ... SELECT or MERGE statement. The staging table
+// lives in a single connection, so a *sql.DB is asked for one for the whole upsert. The staging table is
+// dropped at the end. The rejected rows are given to the dead letter sink as by Exec.
+func (b *Bulk) StagedUpsert(ctx context.Context, db Execer) (*Result, error) {
+	if err := b.ready(); err != nil {
+		return nil, err
+	}
+	s, ok := b.dialect.(Stager)
+	if !ok {
+		return nil, fmt.Errorf("ERROR: The dialect %T can't create staging tables", b.dialect)
+	}
+	head, clause, err := b.conflictClause(true)
+	if err != nil {
+		return nil, err
+	}
+	head = b.withHints(head)
+	head, clause = b.withComment(head, clause)
+	if c, ok := db.(connector); ok {
+		conn, err := c.Conn(ctx)
+		if err != nil {
+			return nil, err
+		}
+		defer conn.Close()
+		db = conn
+	}
+
+	name := b.table
+	if i := strings.LastIndexByte(name, '.'); i >= 0 {
+		name = name[i+1:]
+	}
+	staging := b.plainBulk()
+	staging.table = s.StagingName(name)
+	staging.backend = b.backend
+	if _, err := db.ExecContext(ctx, s.CreateStaging(b.quote(staging.table), b.quote(b.table))); err != nil {
+		return nil, err
+	}
+	defer func() {
+		// The staging table is dropped even if ctx is canceled, as the connection may go back to the pool
+		dropCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), dropTimeout)
+		defer cancel()
+		db.ExecContext(dropCtx, "DROP TABLE "+b.quote(staging.table))
+	}()
+
+	staging.vals, staging.rows, staging.resumeRow = b.vals, b.rows, b.resumeRow
+	if b.stats == nil {
+		b.stats = &statsRecorder{}
+	}
+	b.stats.reset()
+	defer b.stats.finish(time.Now())
+	result, err := staging.Exec(ctx, db, false)
+	b.stats.merge(staging.Stats())
+	if err == nil {
+		// The VALUES list of the upsert is replaced by the rows of the staging table
+		columns := strings.Join(b.quoteAll(b.columns), ", ")
+		str := strings.TrimSuffix(head, "VALUES ") + selectRows("SELECT "+columns+" FROM "+b.quote(staging.table), clause)
+		var res sql.Result
+		if res, err = db.ExecContext(ctx, str); err == nil {
+			err = result.add(res)
+		}
+	}
+	return result, b.sendDeadLetters(ctx, result, err)
+}
@@ -0,0 +1,104 @@
+package bulk
+
+import (
+	"context"
+	"fmt"
+	"testing"
+)
+
+// countingSink is a DeadLetterSink counting the rows it receives.
+type countingSink struct {
+	rows int
+}
+
+func (s *countingSink) WriteRejects(ctx context.Context, table string, columns []string, rows []*RowError) error {
+	s.rows += len(rows)
+	return nil
+}
+
+func TestStagedUpsert(t *testing.T) {
+	r := NewRecorder()
+	b := New(Postgres)
+	b.Init("t", "id")
+	b.SetConflictTarget("id")
+	b.PrepareValues(1)
+	b.PrepareValues(2)
+	if _, err := b.StagedUpsert(context.Background(), r.DB()); err != nil {
+		t.Fatal(err)
+	}
+	err := r.Expect(
+		Statement{SQL: `CREATE TEMPORARY TABLE "t_staging" (LIKE "t" INCLUDING DEFAULTS)`},
+		Statement{SQL: `INSERT INTO "t_staging"("id") VALUES ($1),($2)`, Args: []interface{}{1, 2}},
+		Statement{SQL: `INSERT INTO "t"("id") SELECT "id" FROM "t_staging" ON CONFLICT ("id") DO UPDATE SET "id"=EXCLUDED."id"`},
+		Statement{SQL: `DROP TABLE "t_staging"`},
+	)
+	if err != nil {
+		t.Error(err)
+	}
+}
+
+func TestStagedUpsertDialect(t *testing.T) {
+	b := New(SQLite)
+	b.Init("t", "id")
+	b.SetConflictTarget("id")
+	b.PrepareValues(1)
+	if _, err := b.StagedUpsert(context.Background(), NewRecorder().DB()); err == nil {
+		t.Error("expected an error for a dialect without staging tables")
+	}
+}
+
+func TestStagedUpsertCanceled(t *testing.T) {
+	r := NewRecorder()
+	b := New(Postgres)
+	b.Init("t", "id")
+	b.SetConflictTarget("id")
+	b.PrepareValues(1)
+	ctx, cancel := context.WithCancel(context.Background())
+	r.SetResponder(func(s Statement) (int64, error) {
+		if s.SQL == `INSERT INTO "t_staging"("id") VALUES ($1)` {
+			cancel()
+			return 0, context.Canceled
+		}
+		return 0, nil
+	})
+	if _, err := b.StagedUpsert(ctx, r.DB()); err == nil {
+		t.Fatal("expected the error of the canceled load")
+	}
+	// The staging table is dropped even though ctx is canceled
+	statements := r.Statements()
+	if last := statements[len(statements)-1].SQL; last != `DROP TABLE "t_staging"` {
+		t.Errorf("the last statement is %q, expected the drop of the staging table", last)
+	}
+}
+
+func TestStagedUpsertDeadLetter(t *testing.T) {
+	r := NewRecorder()
+	sink := &countingSink{}
+	b := New(Postgres)
+	b.Init("t", "id")
+	b.SetConflictTarget("id")
+	b.SetDeadLetter(sink)
+	b.SetLenientValidation(true)
+	b.SetValidator("id", func(v interface{}) error {
+		if v.(int) < 0 {
+			return fmt.Errorf("negative id")
+		}
+		return nil
+	})
+	b.PrepareValues(1)
+	b.PrepareValues(-1)
+	ctx := context.Background()
+	if _, err := b.StagedUpsert(ctx, r.DB()); err != nil {
+		t.Fatal(err)
+	}
+	if stats := b.Stats(); stats.Batches != 1 || stats.Rows != 1 {
+		t.Errorf("got the stats %+v, expected the batch of the staging table", stats)
+	}
+	// The rejected row is given to the sink once, even if the upsert runs again
+	if _, err := b.StagedUpsert(ctx, r.DB()); err != nil {
+		t.Fatal(err)
+	}
+	if sink.rows != 1 {
+		t.Errorf("the sink got %v rows, expected 1", sink.rows)
+	}
+}
@@ -0,0 +1,27 @@
+package bulk
+
+// Statement is a generated SQL statement with its arguments.
+type Statement struct {
+	SQL  string
+	Args []interface{}
+}
+
+// BuildStatements returns the statements that Insert would execute with replaceOnDuplicate, one per
+// batch, without executing them. It can be used to inspect or log the generated SQL, or to run the
+// statements with a custom pipeline. The arguments share the memory of the buffered values, and are nil
+// if the values are interpolated (see SetInterpolate).
+func (b *Bulk) BuildStatements(replaceOnDuplicate bool) ([]Statement, error) {
+	head, endStr, batches, err := b.plan(replaceOnDuplicate)
+	if err != nil {
+		return nil, err
+	}
+	statements := make([]Statement, len(batches))
+	for i, bt := range batches {
+		str, args, err := b.batchStatement(head, bt, endStr)
+		if err != nil {
+			return nil, err
+		}
+		statements[i] = Statement{SQL: str, Args: args}
+	}
+	return statements, nil
+}
@@ -0,0 +1,80 @@
+package bulk
+
+import (
+	"sync"
+	"time"
+)
+
+// Stats describes the last insert executed by a Bulk.
+type Stats struct {
+	Rows    int           // Number of rows of the executed batches
+	Batches int           // Number of executed batches
+	Bytes   int64         // Bytes of SQL text of the executed statements
+	Elapsed time.Duration // Wall-clock time of the whole insert
+	Batch   []BatchStats  // Breakdown of the executed batches, in execution order
+}
+
+// BatchStats describes an executed batch.
+type BatchStats struct {
+	Index    int           // Index of the batch in the load, starting at 0
+	Rows     int           // Number of rows in the batch
+	Bytes    int           // Bytes of SQL text of the statement
+	Duration time.Duration // Time spent executing the batch, including retries
+}
+
+// RowsPerSecond returns the insert throughput.
+func (s Stats) RowsPerSecond() float64 {
+	if s.Elapsed <= 0 {
+		return 0
+	}
+	return float64(s.Rows) / s.Elapsed.Seconds()
+}
+
+// statsRecorder collects the Stats of an insert. It's safe for concurrent use.
+type statsRecorder struct {
+	mu    sync.Mutex
+	stats Stats
+}
+
+// add records an executed batch.
+func (r *statsRecorder) add(bs BatchStats) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.stats.Rows += bs.Rows
+	r.stats.Batches++
+	r.stats.Bytes += int64(bs.Bytes)
+	r.stats.Batch = append(r.stats.Batch, bs)
+}
+
+// merge records the batches of s, the statistics of another insert.
+func (r *statsRecorder) merge(s Stats) {
+	for _, bs := range s.Batch {
+		r.add(bs)
+	}
+}
+
+// reset clears the recorded statistics before a new insert.
+func (r *statsRecorder) reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.stats = Stats{}
+}
+
+// finish records the elapsed time of an insert which started at start.
+func (r *statsRecorder) finish(start time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.stats.Elapsed = time.Since(start)
+}
+
+// Stats returns the statistics of the last Insert, InsertContext or Exec call, including the failed ones.
+func (b *Bulk) Stats() Stats {
+	if b.stats == nil {
+		return Stats{}
+	}
+	b.stats.mu.Lock()
+	defer b.stats.mu.Unlock()
+	s := b.stats.stats
+	s.Batch = append([]BatchStats(nil), s.Batch...)
+	return s
+}
@@ -0,0 +1,67 @@
+package bulk
+
+import (
+	"context"
+	"database/sql"
+	"sync"
+)
+
+// stmtCache prepares each distinct statement once, so the full batches of a load, which share the same
+// SQL, reuse a single prepared statement. It's safe for concurrent use.
+type stmtCache struct {
+	db    Execer
+	mu    sync.Mutex
+	stmts map[string]*sql.Stmt
+}
+
+// newStmtCache returns an empty cache of statements prepared on db.
+func newStmtCache(db Execer) *stmtCache {
+	return &stmtCache{db: db, stmts: map[string]*sql.Stmt{}}
+}
+
+// prepare returns the prepared statement for query, preparing it if it isn't cached yet.
+func (c *stmtCache) prepare(ctx context.Context, query string) (*sql.Stmt, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if stmt, ok := c.stmts[query]; ok {
+		return stmt, nil
+	}
+	stmt, err := c.db.PrepareContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	c.stmts[query] = stmt
+	return stmt, nil
+}
+
+// close closes every cached statement and returns the first error found.
+func (c *stmtCache) close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var firstErr error
+	for query, stmt := range c.stmts {
+		if err := stmt.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+		delete(c.stmts, query)
+	}
+	return firstErr
+}
+
+// Close ends the streaming mode enabled with SetAutoFlush: it stops the periodic flushes, inserts the
+// remaining rows and releases the statements that the Bulk keeps prepared between flushes. The Bulk can
+// still be used after Close.
+func (b *Bulk) Close() error {
+	err := b.stopInterval()
+	if b.autoFlush != nil && err == nil {
+		err = b.flush(b.autoFlush.ctx)
+	}
+	if b.flushStmts == nil {
+		return err
+	}
+	if cerr := b.flushStmts.close(); err == nil {
+		err = cerr
+	}
+	b.flushStmts = nil
+	return err
+}
@@ -0,0 +1,94 @@
+package bulk
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// AddStructs appends one row per element of slice, which must be a slice of structs or of pointers to
+// structs. Each column is filled with the struct field whose `bulk:"column_name"` tag matches it; fields
+// without a tag are matched by their name, ignoring the case. Fields tagged with `bulk:"-"` are skipped.
+// Every column must be matched by a field, otherwise no row is appended and an error is returned. The rows
+// are appended with AddRows, so none is appended if one of them is invalid.
+func (b *Bulk) AddStructs(slice interface{}) error {
+	v := reflect.ValueOf(slice)
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		return fmt.Errorf("ERROR: AddStructs requires a slice of structs, got %T", slice)
+	}
+	elemType := v.Type().Elem()
+	isPtr := elemType.Kind() == reflect.Ptr
+	if isPtr {
+		elemType = elemType.Elem()
+	}
+	if elemType.Kind() != reflect.Struct {
+		return fmt.Errorf("ERROR: AddStructs requires a slice of structs, got %T", slice)
+	}
+	fields, err := b.structFields(elemType)
+	if err != nil {
+		return err
+	}
+
+	rows := make([][]interface{}, v.Len())
+	for i := range rows {
+		elem := v.Index(i)
+		if isPtr {
+			if elem.IsNil() {
+				return fmt.Errorf("ERROR: AddStructs found a nil element at index %v", i)
+			}
+			elem = elem.Elem()
+		}
+		rows[i] = make([]interface{}, len(fields))
+		for j, index := range fields {
+			rows[i][j] = elem.FieldByIndex(index).Interface()
+		}
+	}
+	return b.AddRows(rows)
+}
+
+// structFields returns, for every column, the index of the field of t which holds its value.
+func (b *Bulk) structFields(t reflect.Type) ([][]int, error) {
+	tagged := map[string][]int{}
+	named := map[string][]int{}
+	collectFields(t, "bulk", nil, tagged, named)
+
+	columns := b.inputColumns()
+	fields := make([][]int, len(columns))
+	for i, c := range columns {
+		if index, ok := tagged[c]; ok {
+			fields[i] = index
+		} else if index, ok := named[strings.ToLower(c)]; ok {
+			fields[i] = index
+		} else {
+			return nil, fmt.Errorf("ERROR: The struct %v has no field for the column %v", t, c)
+		}
+	}
+	return fields, nil
+}
+
+// collectFields adds the exported fields of t to tagged (by their key tag) or named (by lowercase name). The
+// fields of embedded structs are promoted, as Go does.
+func collectFields(t reflect.Type, key string, parent []int, tagged, named map[string][]int) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		index := append(append([]int{}, parent...), i)
+		tag := f.Tag.Get(key)
+		if tag == "-" {
+			continue
+		}
+		if f.Anonymous && tag == "" && f.Type.Kind() == reflect.Struct {
+			collectFields(f.Type, key, index, tagged, named)
+			continue
+		}
+		if f.PkgPath != "" { // unexported
+			continue
+		}
+		if tag != "" {
+			if _, ok := tagged[tag]; !ok {
+				tagged[tag] = index
+			}
+		} else if _, ok := named[strings.ToLower(f.Name)]; !ok {
+			named[strings.ToLower(f.Name)] = index
+		}
+	}
+}
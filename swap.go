@@ -0,0 +1,101 @@
+package bulk
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// SwapTables holds the quoted names of the tables involved in a shadow table swap.
+type SwapTables struct {
+	Table, Shadow, Old string // Names qualified with the schema of the table, if any
+	TableName, OldName string // Names of Table and Old without the schema
+}
+
+// Swapper is implemented by the dialects which can atomically replace a table with another one, such as
+// MySQL and Postgres.
+type Swapper interface {
+	// CreateLike returns the statement creating the table shadow with the structure of table.
+	CreateLike(shadow, table string) string
+	// Swap returns the statements renaming t.Table to t.Old and t.Shadow to t.Table, which are executed
+	// inside a transaction.
+	Swap(t *SwapTables) []string
+}
+
+func (mysqlDialect) CreateLike(shadow, table string) string {
+	return "CREATE TABLE " + shadow + " LIKE " + table
+}
+
+func (mysqlDialect) Swap(t *SwapTables) []string {
+	return []string{"RENAME TABLE " + t.Table + " TO " + t.Old + ", " + t.Shadow + " TO " + t.Table}
+}
+
+func (postgresDialect) CreateLike(shadow, table string) string {
+	return "CREATE TABLE " + shadow + " (LIKE " + table + " INCLUDING ALL)"
+}
+
+func (postgresDialect) Swap(t *SwapTables) []string {
+	return []string{
+		"ALTER TABLE " + t.Table + " RENAME TO " + t.OldName,
+		"ALTER TABLE " + t.Shadow + " RENAME TO " + t.TableName,
+	}
+}
+
+// SwapLoad replaces the contents of the table without blocking its readers: the rows are inserted into
+// a new table with the same structure, named after the table with the _shadow suffix, which then takes the
+// place of the table, and the old table is dropped. If the load fails, the shadow table is dropped and the
+// table is left untouched. The indexes are copied, while the foreign keys and the triggers aren't. The
+// table statements commit any transaction on MySQL, so db shouldn't be a *sql.Tx.
+func (b *Bulk) SwapLoad(ctx context.Context, db Execer) (*Result, error) {
+	if err := b.ready(); err != nil {
+		return nil, err
+	}
+	s, ok := b.dialect.(Swapper)
+	if !ok {
+		return nil, fmt.Errorf("ERROR: The dialect %T can't swap tables", b.dialect)
+	}
+	schema, name := "", b.table
+	if i := strings.LastIndexByte(b.table, '.'); i >= 0 {
+		schema, name = b.table[:i+1], b.table[i+1:]
+	}
+	t := &SwapTables{
+		Table:     b.quote(b.table),
+		Shadow:    b.quote(schema + name + "_shadow"),
+		Old:       b.quote(schema + name + "_old"),
+		TableName: b.quote(name),
+		OldName:   b.quote(name + "_old"),
+	}
+	exec := func(str string) error {
+		_, err := db.ExecContext(ctx, str)
+		return err
+	}
+
+	// The tables left by a failed load are replaced
+	for _, table := range []string{t.Shadow, t.Old} {
+		if err := exec("DROP TABLE IF EXISTS " + table); err != nil {
+			return nil, err
+		}
+	}
+	if err := exec(s.CreateLike(t.Shadow, t.Table)); err != nil {
+		return nil, err
+	}
+	shadow := *b
+	shadow.table = schema + name + "_shadow"
+	shadow.truncate = false
+	result, err := shadow.Exec(ctx, db, false)
+	if err == nil {
+		err = withTx(ctx, db, func(tx Execer) error {
+			for _, str := range s.Swap(t) {
+				if _, err := tx.ExecContext(ctx, str); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	}
+	if err != nil {
+		exec("DROP TABLE IF EXISTS " + t.Shadow)
+		return result, err
+	}
+	return result, exec("DROP TABLE " + t.Old)
+}
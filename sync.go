@@ -0,0 +1,232 @@
+package bulk
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SyncResult tells the changes made by Sync.
+type SyncResult struct {
+	Inserted int // Rows which weren't in the table
+	Updated  int // Rows whose values changed
+	Deleted  int // Rows which weren't buffered
+}
+
+// Sync makes the table hold exactly the buffered rows: the rows which aren't in the table are inserted,
+// the ones whose values changed are updated and the ones which aren't buffered are deleted. The rows are
+// identified by the keys columns, which must be a primary key or unique index of the table, as the
+// updates are upserts targeting them. Every row of the table is read to find the changes, comparing the
+// values by their text, and the changes are made inside a transaction, the one of db if it's a *sql.Tx.
+// Stats returns the batches of the inserts and updates, and the rejected rows are given to the dead letter
+// sink as by Exec. With constant columns, only the rows of the table holding their values are read and
+// deleted, so every buffered row must hold the same ones.
+func (b *Bulk) Sync(ctx context.Context, db Execer, keys ...string) (*SyncResult, error) {
+	if err := b.ready(); err != nil && err != ErrNoRows {
+		return nil, err
+	}
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("ERROR: Sync requires at least one key column")
+	}
+	keyIndex := make([]int, len(keys))
+	for i, k := range keys {
+		keyIndex[i] = -1
+		for j, c := range b.columns {
+			if c == k {
+				keyIndex[i] = j
+			}
+		}
+		if keyIndex[i] < 0 {
+			return nil, fmt.Errorf("ERROR: The key column %v isn't inserted", k)
+		}
+	}
+
+	// The buffered rows by key
+	desired := make(map[string]int, b.rows)
+	for r := 0; r < b.rows; r++ {
+		k := rowKey(b.vals[r*b.valuesPerRow:(r+1)*b.valuesPerRow], keyIndex)
+		if _, ok := desired[k]; ok {
+			return nil, fmt.Errorf("ERROR: The key of the row %v is repeated", r)
+		}
+		desired[k] = r
+	}
+	// The constant columns scope the rows of the table which are synced, e.g. to the ones of a tenant
+	n := len(b.inputColumns())
+	for i, c := range b.constants {
+		for r := 0; r < b.rows; r++ {
+			if valueText(b.vals[r*b.valuesPerRow+n+i]) != valueText(c.value) {
+				return nil, fmt.Errorf("ERROR: Sync requires the same value of the constant column %v in every row", c.name)
+			}
+		}
+	}
+
+	result := &SyncResult{}
+	if b.stats == nil {
+		b.stats = &statsRecorder{}
+	}
+	b.stats.reset()
+	defer b.stats.finish(time.Now())
+	err := b.withTx(ctx, db, func(tx Execer) error {
+		str := "SELECT " + strings.Join(b.quoteAll(b.columns), ", ") + " FROM " + b.quote(b.table)
+		args := make([]interface{}, len(b.constants))
+		for i, c := range b.constants {
+			if i == 0 {
+				str += " WHERE "
+			} else {
+				str += " AND "
+			}
+			str += b.quote(c.name) + " = " + b.dialect.Placeholder(i+1)
+			args[i] = c.value
+		}
+		rows, err := tx.QueryContext(ctx, str, args...)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		found := make([]bool, b.rows)
+		var updates []int
+		deletes := b.plainBulk()
+		deletes.columns = keys
+		deletes.valuesPerRow = len(keys)
+		for rows.Next() {
+			vals := make([]interface{}, len(b.columns))
+			dest := make([]interface{}, len(b.columns))
+			for i := range vals {
+				dest[i] = &vals[i]
+			}
+			if err := rows.Scan(dest...); err != nil {
+				return err
+			}
+			r, ok := desired[rowKey(vals, keyIndex)]
+			if !ok {
+				for _, i := range keyIndex {
+					deletes.vals = append(deletes.vals, vals[i])
+				}
+				deletes.rows++
+				continue
+			}
+			found[r] = true
+			// The appended columns, such as the audit timestamps, don't make a row change
+			if !equalRows(vals[:n], b.vals[r*b.valuesPerRow:r*b.valuesPerRow+n]) {
+				updates = append(updates, r)
+			}
+		}
+		if err := rows.Err(); err != nil {
+			return err
+		}
+		rows.Close()
+
+		inserts := b.plainBulk()
+		for r, ok := range found {
+			if !ok {
+				inserts.addRow(b, r)
+			}
+		}
+		upserts := b.plainBulk()
+		upserts.conflictTarget = keys
+		for _, r := range updates {
+			upserts.addRow(b, r)
+		}
+
+		if inserts.rows > 0 {
+			_, err := inserts.Exec(ctx, tx, false)
+			b.stats.merge(inserts.Stats())
+			if err != nil {
+				return err
+			}
+		}
+		if upserts.rows > 0 {
+			_, err := upserts.Exec(ctx, tx, true)
+			b.stats.merge(upserts.Stats())
+			if err != nil {
+				return err
+			}
+		}
+		if deletes.rows > 0 {
+			if _, err := (&BulkDelete{b: deletes, where: b.constants}).DeleteContext(ctx, tx); err != nil {
+				return err
+			}
+		}
+		result.Inserted, result.Updated, result.Deleted = inserts.rows, upserts.rows, deletes.rows
+		return nil
+	})
+	if err = b.sendDeadLetters(ctx, &Result{}, err); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// plainBulk returns an empty Bulk for the same table and columns as b, inserting in a single sequence of
+// plain statements, which Sync and StagedUpsert use for their steps. It records its own statistics, and
+// neither sends rows to the dead letter sink nor quarantines them, which stay the job of b.
+func (b *Bulk) plainBulk() *Bulk {
+	c := *b
+	c.vals, c.rows, c.resumeRow = nil, 0, 0
+	c.conflictTarget, c.updateColumns, c.updateExprs = nil, nil, nil
+	c.conflictWhere, c.updateWhere = "", ""
+	c.ignoreDuplicates, c.replaceInto, c.truncate = false, false, false
+	c.atomic, c.commitEvery, c.parallelism, c.continueOnError = false, 0, 0, false
+	c.autoFlush, c.flushStmts, c.interval, c.backend = nil, nil, nil, nil
+	c.sessionSetup, c.sessionTeardown = nil, nil
+	c.disableKeys, c.dropIndexes, c.createIndexes = false, nil, nil
+	c.checkpoint, c.checkpointSkip = nil, 0
+	c.received, c.rejected, c.deadLettered = 0, nil, 0
+	c.deadLetter, c.quarantine = nil, nil
+	c.stats = &statsRecorder{}
+	return &c
+}
+
+// addRow appends the row r of src.
+func (b *Bulk) addRow(src *Bulk, r int) {
+	b.vals = append(b.vals, src.vals[r*src.valuesPerRow:(r+1)*src.valuesPerRow]...)
+	b.rows++
+}
+
+// rowKey returns the text of the values of the key columns of row.
+func rowKey(row []interface{}, keyIndex []int) string {
+	var sb strings.Builder
+	for _, i := range keyIndex {
+		sb.WriteString(valueText(row[i]))
+		sb.WriteByte(0)
+	}
+	return sb.String()
+}
+
+// equalRows reports whether the values of a and b have the same text.
+func equalRows(a, b []interface{}) bool {
+	for i := range a {
+		if valueText(a[i]) != valueText(b[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// valueText returns the text of v as the database would return it, so the values read from the table can
+// be compared with the buffered ones.
+func valueText(v interface{}) string {
+	dv, err := driver.DefaultParameterConverter.ConvertValue(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	switch v := dv.(type) {
+	case nil:
+		return "\x00NULL"
+	case []byte:
+		return string(v)
+	case bool:
+		if v {
+			return "1"
+		}
+		return "0"
+	case float64:
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	case time.Time:
+		return v.UTC().Format(time.RFC3339Nano)
+	}
+	return fmt.Sprint(dv)
+}
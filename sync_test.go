@@ -0,0 +1,164 @@
+package bulk
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"io"
+	"testing"
+)
+
+// tableConnector opens connections to a Recorder whose queries return the rows of a table.
+type tableConnector struct {
+	r    *Recorder
+	rows [][]driver.Value
+}
+
+func (c tableConnector) Connect(context.Context) (driver.Conn, error) {
+	return tableConn{recorderConn{c.r}, c.rows}, nil
+}
+
+func (c tableConnector) Driver() driver.Driver {
+	return recorderDriver{c.r}
+}
+
+type tableConn struct {
+	recorderConn
+	rows [][]driver.Value
+}
+
+func (c tableConn) Prepare(query string) (driver.Stmt, error) {
+	return tableStmt{recorderStmt{r: c.r, query: query}, c.rows}, nil
+}
+
+func (c tableConn) Begin() (driver.Tx, error) {
+	if _, err := c.recorderConn.Begin(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+type tableStmt struct {
+	recorderStmt
+	rows [][]driver.Value
+}
+
+func (s tableStmt) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	if _, err := s.recorderStmt.QueryContext(ctx, args); err != nil {
+		return nil, err
+	}
+	return &tableRows{rows: s.rows}, nil
+}
+
+type tableRows struct {
+	rows [][]driver.Value
+}
+
+func (r *tableRows) Columns() []string {
+	if len(r.rows) == 0 {
+		return nil
+	}
+	return make([]string, len(r.rows[0]))
+}
+
+func (r *tableRows) Close() error {
+	return nil
+}
+
+func (r *tableRows) Next(dest []driver.Value) error {
+	if len(r.rows) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.rows[0])
+	r.rows = r.rows[1:]
+	return nil
+}
+
+func TestSync(t *testing.T) {
+	r := NewRecorder()
+	db := sql.OpenDB(tableConnector{r, [][]driver.Value{{int64(1), "a"}, {int64(2), "x"}, {int64(3), "c"}}})
+	b := New(Postgres)
+	b.Init("t", "id", "name")
+	b.PrepareValues(1, "a")
+	b.PrepareValues(2, "b")
+	b.PrepareValues(4, "d")
+	result, err := b.Sync(context.Background(), db, "id")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *result != (SyncResult{Inserted: 1, Updated: 1, Deleted: 1}) {
+		t.Errorf("got %+v", *result)
+	}
+	err = r.Expect(
+		Statement{SQL: "BEGIN"},
+		Statement{SQL: `SELECT "id", "name" FROM "t"`},
+		Statement{SQL: `INSERT INTO "t"("id", "name") VALUES ($1,$2)`, Args: []interface{}{4, "d"}},
+		Statement{SQL: `INSERT INTO "t"("id", "name") VALUES ($1,$2) ON CONFLICT ("id") DO UPDATE SET "id"=EXCLUDED."id","name"=EXCLUDED."name"`, Args: []interface{}{2, "b"}},
+		Statement{SQL: `DELETE FROM "t" WHERE "id" IN ($1)`, Args: []interface{}{int64(3)}},
+		Statement{SQL: "COMMIT"},
+	)
+	if err != nil {
+		t.Error(err)
+	}
+}
+
+func TestSyncConstantColumn(t *testing.T) {
+	r := NewRecorder()
+	db := sql.OpenDB(tableConnector{r, [][]driver.Value{{int64(1), "a", int64(7)}, {int64(3), "c", int64(7)}}})
+	b := New(Postgres)
+	b.WithConstantColumn("tenant", 7)
+	b.Init("t", "id", "name")
+	b.PrepareValues(1, "a")
+	b.PrepareValues(2, "b")
+	result, err := b.Sync(context.Background(), db, "id")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *result != (SyncResult{Inserted: 1, Deleted: 1}) {
+		t.Errorf("got %+v", *result)
+	}
+	// Only the rows of the tenant are read and deleted
+	err = r.Expect(
+		Statement{SQL: "BEGIN"},
+		Statement{SQL: `SELECT "id", "name", "tenant" FROM "t" WHERE "tenant" = $1`, Args: []interface{}{7}},
+		Statement{SQL: `INSERT INTO "t"("id", "name", "tenant") VALUES ($1,$2,$3)`, Args: []interface{}{2, "b", 7}},
+		Statement{SQL: `DELETE FROM "t" WHERE "id" IN ($1) AND "tenant" = $2`, Args: []interface{}{int64(3), 7}},
+		Statement{SQL: "COMMIT"},
+	)
+	if err != nil {
+		t.Error(err)
+	}
+
+	b.WithConstantColumn("tenant", 8)
+	b.PrepareValues(5, "e")
+	_, err = b.Sync(context.Background(), db, "id")
+	checkError(t, err, "Sync requires the same value of the constant column tenant in every row")
+}
+
+func TestSyncDeadLetter(t *testing.T) {
+	r := NewRecorder()
+	db := sql.OpenDB(tableConnector{r: r})
+	sink := &countingSink{}
+	b := New(Postgres)
+	b.Init("t", "id")
+	b.SetDeadLetter(sink)
+	b.SetLenientValidation(true)
+	b.SetValidator("id", func(v interface{}) error {
+		if v.(int) < 0 {
+			return fmt.Errorf("negative id")
+		}
+		return nil
+	})
+	b.PrepareValues(1)
+	b.PrepareValues(-1)
+	if _, err := b.Sync(context.Background(), db, "id"); err != nil {
+		t.Fatal(err)
+	}
+	if stats := b.Stats(); stats.Batches != 1 || stats.Rows != 1 {
+		t.Errorf("got the stats %+v, expected the batch of the insert", stats)
+	}
+	if sink.rows != 1 {
+		t.Errorf("the sink got %v rows, expected 1", sink.rows)
+	}
+}
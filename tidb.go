@@ -0,0 +1,51 @@
+package bulk
+
+// TiDB uses the MySQL syntax. Its batches have at most 1000 rows and the transactions started by the Bulk
+// at most 100000, see TxRowLimiter, so they stay well below the default txn-total-size-limit of 100 MB.
+// SetHints adds optimizer hints to the statements, e.g. SET_VAR(...). TiDB accepts the DISABLE KEYS
+// statements of SetDisableKeys but ignores them, as they only apply to the MyISAM tables.
+var TiDB Dialect = tidbDialect{}
+
+// tidbDialect is MySQL with smaller batches and transactions.
+type tidbDialect struct {
+	mysqlDialect
+}
+
+func (tidbDialect) MaxRows() int {
+	return 1000
+}
+
+func (tidbDialect) MaxTxRows() int {
+	return 100000
+}
+
+// TxRowLimiter is implemented by the dialects which limit the size of a transaction, such as TiDB. The
+// loads running inside transactions started by the Bulk, with SetAtomic or SetCommitEvery, are split in
+// several transactions which never exceed that number of rows. So a load which is larger than the limit
+// is no longer atomic: if a transaction fails, Result.Committed tells the row from which it can be
+// resumed. The loads of SetTruncate, and the ones run inside a *sql.Tx of the caller, aren't split.
+type TxRowLimiter interface {
+	// MaxTxRows returns the maximum number of rows inserted by a single transaction.
+	MaxTxRows() int
+}
+
+// SetMaxTxRows sets the maximum number of rows inserted by a transaction started by the Bulk, overriding
+// the limit of the dialect, see TxRowLimiter. 0 restores the limit of the dialect and a negative n
+// disables it.
+func (b *Bulk) SetMaxTxRows(n int) {
+	b.maxTxRows = n
+}
+
+// txRowLimit returns the maximum number of rows of a transaction, or 0 if the transactions aren't split.
+func (b *Bulk) txRowLimit() int {
+	if b.truncate || b.maxTxRows < 0 {
+		return 0
+	}
+	if b.maxTxRows > 0 {
+		return b.maxTxRows
+	}
+	if l, ok := b.dialect.(TxRowLimiter); ok {
+		return l.MaxTxRows()
+	}
+	return 0
+}
@@ -0,0 +1,24 @@
+package bulk
+
+import "testing"
+
+func TestTiDB(t *testing.T) {
+	if _, ok := TiDB.(BinaryUUIDs); !ok {
+		t.Error("TiDB should send the UUIDs as binary, as MySQL")
+	}
+	if l, ok := TiDB.(TxRowLimiter); !ok || l.MaxTxRows() != 100000 {
+		t.Error("TiDB should limit the rows of its transactions")
+	}
+	b := New(TiDB)
+	b.Init("t", "id")
+	for i := 0; i < 1500; i++ {
+		b.PrepareValues(i)
+	}
+	statements, err := b.BuildStatements(false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(statements) != 2 || len(statements[0].Args) != 1000 {
+		t.Errorf("got %v statements, expected 2 with batches of 1000 rows", len(statements))
+	}
+}
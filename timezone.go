@@ -0,0 +1,64 @@
+package bulk
+
+import "time"
+
+// timeZone is the conversion of the times of a column set with SetTimeZone.
+type timeZone struct {
+	loc    *time.Location
+	layout string
+}
+
+// SetTimeZone converts the time.Time values of columns, or of every column if none is given, to loc,
+// usually time.UTC, so the inserted times don't depend on the time zone of the host which produced them.
+// If layout isn't empty, the times are sent as their text formatted with layout, e.g. "2006-01-02
+// 15:04:05.999999", instead of letting the driver format them. The columns given override the setting of
+// every column. A nil loc removes the conversion.
+func (b *Bulk) SetTimeZone(loc *time.Location, layout string, columns ...string) {
+	if b.timeZones == nil {
+		b.timeZones = map[string]timeZone{}
+	}
+	if len(columns) == 0 {
+		columns = []string{""}
+	}
+	for _, c := range columns {
+		if loc == nil {
+			delete(b.timeZones, c)
+		} else {
+			b.timeZones[c] = timeZone{loc: loc, layout: layout}
+		}
+	}
+	b.columnTimeZones = nil
+}
+
+// normalizeTimes converts the time.Time values of row, which is in the buffer and whose NULL values are
+// explicit, as set with SetTimeZone.
+func (b *Bulk) normalizeTimes(row []interface{}) {
+	if len(b.timeZones) == 0 {
+		return
+	}
+	if len(b.columnTimeZones) != len(b.columns) {
+		b.columnTimeZones = make([]*timeZone, len(b.columns))
+		for i, c := range b.columns {
+			z, ok := b.timeZones[c]
+			if !ok {
+				z, ok = b.timeZones[""]
+			}
+			if ok {
+				b.columnTimeZones[i] = &z
+			}
+		}
+	}
+	for i, v := range row {
+		t, ok := v.(time.Time)
+		if !ok || i >= len(b.columnTimeZones) || b.columnTimeZones[i] == nil {
+			continue
+		}
+		z := b.columnTimeZones[i]
+		t = t.In(z.loc)
+		if z.layout != "" {
+			row[i] = t.Format(z.layout)
+		} else {
+			row[i] = t
+		}
+	}
+}
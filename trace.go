@@ -0,0 +1,45 @@
+package bulk
+
+import (
+	"context"
+	"log/slog"
+)
+
+// Tracer starts the spans of the inserts and of their batches, so the loads show up in distributed
+// traces, such as OpenTelemetry ones. The package doesn't depend on any tracing library: an adapter
+// starts a span of the library with the name and the attributes, and returns it wrapped in a Span.
+type Tracer interface {
+	// Start starts a span, child of the one in ctx, and returns the context holding it, which is used for
+	// the statements executed during the span.
+	Start(ctx context.Context, name string, attrs ...slog.Attr) (context.Context, Span)
+}
+
+// Span is a span started by a Tracer.
+type Span interface {
+	// SetAttributes adds attributes known after starting the span.
+	SetAttributes(attrs ...slog.Attr)
+	// End ends the span, recording err if it isn't nil.
+	End(err error)
+}
+
+// SetTracer makes every insert start a "bulk.insert" span, with the table and the number of rows, and
+// every batch a "bulk.batch" span, child of it, with the batch index, its rows and the bytes of its
+// statement. A nil t disables the tracing, which is the default.
+func (b *Bulk) SetTracer(t Tracer) {
+	b.tracer = t
+}
+
+// startSpan starts a span with the tracer, or a span doing nothing if there's no tracer.
+func (b *Bulk) startSpan(ctx context.Context, name string, attrs ...slog.Attr) (context.Context, Span) {
+	if b.tracer == nil {
+		return ctx, nopSpan{}
+	}
+	return b.tracer.Start(ctx, name, attrs...)
+}
+
+// nopSpan is a Span doing nothing.
+type nopSpan struct{}
+
+func (nopSpan) SetAttributes(attrs ...slog.Attr) {}
+
+func (nopSpan) End(err error) {}
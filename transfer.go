@@ -0,0 +1,56 @@
+package bulk
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// Querier is implemented by the handles which can run a query, such as *sql.DB, *sql.Conn and *sql.Tx.
+type Querier interface {
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+}
+
+// Copy inserts the result of query on src into the columns cols of table in dst, streaming the rows so the
+// memory used doesn't depend on the size of the result, and returns the number of rows copied. The
+// statements are written for MySQL: for other databases or to set any option, use Bulk.CopyFrom. Since the
+// rows are inserted while the result is read, src and dst can't share a connection, e.g. the same *sql.Tx.
+func Copy(ctx context.Context, src Querier, query string, dst Execer, table string, cols ...string) (int, error) {
+	b := New(MySQL)
+	b.Init(table, cols...)
+	return b.CopyFrom(ctx, src, dst, query)
+}
+
+// CopyFrom inserts the result of query with args on src into dst, flushing the rows as soon as they fill
+// a batch, or the number of rows given to SetAutoFlush, and returns the number of rows copied. The result
+// must have the columns given to Init, in the same order. The database set with SetAutoFlush, if any, is
+// restored afterwards.
+func (b *Bulk) CopyFrom(ctx context.Context, src Querier, dst Execer, query string, args ...interface{}) (int, error) {
+	if err := b.ready(); err != nil && err != ErrNoRows {
+		return 0, err
+	}
+	if b.interval != nil {
+		return 0, fmt.Errorf("ERROR: CopyFrom can't be used along with SetFlushInterval")
+	}
+	// The flushes of the copy use their own statements
+	saved, savedStmts := b.autoFlush, b.flushStmts
+	flush := &autoFlush{ctx: ctx, db: dst}
+	if saved != nil {
+		flush.rows = saved.rows
+		flush.replaceOnDuplicate = saved.replaceOnDuplicate
+	}
+	b.autoFlush, b.flushStmts = flush, nil
+	defer func() {
+		if b.flushStmts != nil {
+			b.flushStmts.close()
+		}
+		b.autoFlush, b.flushStmts = saved, savedStmts
+	}()
+
+	rows, err := src.QueryContext(ctx, query, args...)
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+	return b.AddFromRows(rows)
+}
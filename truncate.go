@@ -0,0 +1,32 @@
+package bulk
+
+import "context"
+
+// Truncater is implemented by the dialects whose TRUNCATE TABLE can be rolled back, such as Postgres.
+// The other dialects clear the table with DELETE FROM, since their TRUNCATE commits the transaction.
+type Truncater interface {
+	// Truncate returns the statement removing every row of table.
+	Truncate(table string) string
+}
+
+func (postgresDialect) Truncate(table string) string {
+	return "TRUNCATE TABLE " + table
+}
+
+// SetTruncate makes Insert replace the contents of the table: every row is deleted and the buffered rows
+// are inserted inside a single transaction, so the readers see either the old rows or the new ones. The
+// db passed to Insert must implement TxBeginner or be a *sql.Tx, like for SetAtomic. It can't be combined
+// with the streaming mode, where each flush would delete the rows of the previous ones.
+func (b *Bulk) SetTruncate(truncate bool) {
+	b.truncate = truncate
+}
+
+// clearTable deletes every row of the table with tx.
+func (b *Bulk) clearTable(ctx context.Context, tx Execer) error {
+	str := "DELETE FROM " + b.quote(b.table)
+	if t, ok := b.dialect.(Truncater); ok {
+		str = t.Truncate(b.quote(b.table))
+	}
+	_, err := tx.ExecContext(ctx, str)
+	return err
+}
@@ -0,0 +1,107 @@
+package bulk
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// TxBeginner is implemented by the handles which can start a transaction, such as *sql.DB and *sql.Conn.
+type TxBeginner interface {
+	BeginTx(ctx context.Context, opts *sql.TxOptions) (*sql.Tx, error)
+}
+
+// SetAtomic makes Insert run all the batches inside a single transaction, which is rolled back if any
+// of them fails, so either all the rows are inserted or none, unless the dialect limits the size of the
+// transactions, see TxRowLimiter. The db passed to Insert must implement TxBeginner, or be a *sql.Tx, in
+// which case the caller's transaction is used as is.
+func (b *Bulk) SetAtomic(atomic bool) {
+	b.atomic = atomic
+}
+
+// SetCommitEvery makes Insert commit the rows every n batches, each group of batches running inside its
+// own transaction. If a batch fails, the rows of its group are rolled back and Result.Committed tells
+// the row from which the load can be resumed with SetResumeRow. It's ignored if SetAtomic is enabled.
+func (b *Bulk) SetCommitEvery(n int) {
+	b.commitEvery = n
+}
+
+// SetResumeRow makes Insert skip the rows before row, which were inserted by a previous load. It's
+// usually set to the Result.Committed of the failed load.
+func (b *Bulk) SetResumeRow(row int) {
+	b.resumeRow = row
+}
+
+// txGroups splits batches in the groups which are committed together. The groups inside transactions
+// don't exceed the txRowLimit, unless a single batch does.
+func (b *Bulk) txGroups(batches []batch) [][]batch {
+	n := 1
+	if b.singleTx() {
+		n = len(batches)
+	} else if b.commitEvery > 0 {
+		n = b.commitEvery
+	}
+	maxRows := 0
+	if b.inTx() {
+		maxRows = b.txRowLimit()
+	}
+	var groups [][]batch
+	start, rows := 0, 0
+	for i, bt := range batches {
+		if i > start && (i-start == n || maxRows > 0 && rows+bt.rows() > maxRows) {
+			groups = append(groups, batches[start:i])
+			start, rows = i, 0
+		}
+		rows += bt.rows()
+	}
+	if start < len(batches) {
+		groups = append(groups, batches[start:])
+	}
+	return groups
+}
+
+// SingleWriter is implemented by the dialects of the databases which allow a single writer at a time, such
+// as SQLite. Unless SetCommitEvery is set, their loads run inside a single transaction, as if SetAtomic
+// was enabled, since committing every batch on its own is much slower and lets other writers interleave.
+// SQLite drivers should start the transactions with BEGIN IMMEDIATE, e.g. with _txlock=immediate for
+// mattn/go-sqlite3, so concurrent loads wait for the lock instead of failing with SQLITE_BUSY.
+type SingleWriter interface {
+	SingleWriter()
+}
+
+func (sqliteDialect) SingleWriter() {}
+
+// singleTx reports whether all the batches run inside a single transaction.
+func (b *Bulk) singleTx() bool {
+	if b.atomic || b.truncate {
+		return true
+	}
+	_, ok := b.dialect.(SingleWriter)
+	return ok && b.commitEvery == 0
+}
+
+// inTx reports whether the batches run inside transactions started by the Bulk.
+func (b *Bulk) inTx() bool {
+	return b.singleTx() || b.commitEvery > 0
+}
+
+// withTx calls fn inside a transaction started on db, which is committed if fn succeeds and rolled back
+// otherwise. If db is already a *sql.Tx, fn runs on it and committing is left to the caller.
+func withTx(ctx context.Context, db Execer, fn func(tx Execer) error) error {
+	if tx, ok := db.(*sql.Tx); ok {
+		return fn(tx)
+	}
+	beginner, ok := db.(TxBeginner)
+	if !ok {
+		return fmt.Errorf("ERROR: %T can't start a transaction", db)
+	}
+	tx, err := beginner.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	if err := fn(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
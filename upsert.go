@@ -0,0 +1,159 @@
+package bulk
+
+import (
+	"fmt"
+)
+
+// SetConflictTarget sets the columns of the unique index or primary key that the Postgres
+// ON CONFLICT clause targets when Insert is called with replaceOnDuplicate, or that the MERGE statements
+// of SQL Server and Oracle match. It's ignored by MySQL, which detects the conflicting key by itself.
+func (b *Bulk) SetConflictTarget(s ...string) {
+	b.conflictTarget = s
+}
+
+// SetConflictWhere sets the predicate of the partial unique index targeted with SetConflictTarget, e.g.
+// "deleted_at IS NULL", written as ON CONFLICT (target) WHERE where on Postgres and SQLite. It's written as
+// given, so it must never contain user input. An empty where targets a full index.
+func (b *Bulk) SetConflictWhere(where string) {
+	b.conflictWhere = where
+}
+
+// OnDuplicateUpdateWhere sets the condition an existing row must meet to be updated when Insert is called
+// with replaceOnDuplicate, so the other rows keep their values, e.g. "users.version < EXCLUDED.version" to
+// only apply newer rows on Postgres, where the existing row is named after the table. The MERGE statements
+// alias the existing row t and the inserted one s. MySQL doesn't support it. It's written as given, so it
+// must never contain user input. An empty where always updates the rows.
+func (b *Bulk) OnDuplicateUpdateWhere(where string) {
+	b.updateWhere = where
+}
+
+// OnDuplicateUpdate sets the columns overwritten with the inserted values when Insert is called with
+// replaceOnDuplicate, so the remaining columns (e.g. created_at) keep their current value. By default
+// every column is updated. The UpdatedAt column of SetAudit is always updated.
+func (b *Bulk) OnDuplicateUpdate(s ...string) {
+	b.updateColumns = s
+}
+
+// OnDuplicateUpdateExpr sets columns which are updated with a SQL expression instead of the inserted
+// value when Insert is called with replaceOnDuplicate, e.g. "counter": "counter + VALUES(counter)" or
+// "updated_at": "NOW()". The expressions are written as given, so they must never contain user input.
+// The remaining columns are still updated as set with OnDuplicateUpdate. In the MERGE statements, the
+// existing row is aliased t and the inserted one s, e.g. "counter": "t.counter + s.counter".
+func (b *Bulk) OnDuplicateUpdateExpr(exprs map[string]string) {
+	b.updateExprs = exprs
+}
+
+// SetIgnoreDuplicates makes Insert skip the rows which collide with an existing key, using INSERT IGNORE
+// on MySQL, ON CONFLICT DO NOTHING on Postgres and a MERGE which only inserts on SQL Server and Oracle.
+// It can't be combined with replaceOnDuplicate.
+func (b *Bulk) SetIgnoreDuplicates(ignore bool) {
+	b.ignoreDuplicates = ignore
+}
+
+// SetReplaceInto makes Insert generate REPLACE INTO statements (MySQL only), which delete the rows
+// colliding with an existing key before inserting the new ones. It can't be combined with
+// replaceOnDuplicate or SetIgnoreDuplicates.
+func (b *Bulk) SetReplaceInto(replace bool) {
+	b.replaceInto = replace
+}
+
+// conflictClause returns the start of the insert statement, up to its VALUES list, and the clause appended
+// to it, which decide how the rows colliding with an existing key are handled.
+func (b *Bulk) conflictClause(replaceOnDuplicate bool) (head, clause string, err error) {
+	c, err := b.conflict(replaceOnDuplicate)
+	if err != nil {
+		return "", "", err
+	}
+	if c == nil {
+		return b.insertHead("INSERT INTO"), "", nil
+	}
+	if m, ok := b.dialect.(Merger); ok {
+		return m.Merge(b.quote(b.table), b.quoteAll(b.columns), c)
+	}
+	verb, clause, err := b.dialect.Upsert(c)
+	if err != nil {
+		return "", "", err
+	}
+	return b.insertHead(verb), clause, nil
+}
+
+// conflict returns how the rows colliding with an existing key are handled, with the identifiers
+// quoted, or nil if they aren't handled.
+func (b *Bulk) conflict(replaceOnDuplicate bool) (*Conflict, error) {
+	if b.replaceInto {
+		if replaceOnDuplicate || b.ignoreDuplicates {
+			return nil, fmt.Errorf("ERROR: REPLACE INTO can't be combined with other duplicate handling")
+		}
+		return &Conflict{Action: DoReplace}, nil
+	}
+	if b.ignoreDuplicates {
+		if replaceOnDuplicate {
+			return nil, fmt.Errorf("ERROR: Duplicates can't be both ignored and replaced")
+		}
+		if err := validateIdentifiers(b.conflictTarget); err != nil {
+			return nil, err
+		}
+		return &Conflict{Action: DoNothing, Target: b.quoteAll(b.conflictTarget), TargetWhere: b.conflictWhere}, nil
+	}
+	if !replaceOnDuplicate {
+		return nil, nil
+	}
+
+	columns := b.columns
+	update := columns
+	if len(b.updateColumns) > 0 {
+		for _, u := range b.updateColumns {
+			if !contains(columns, u) {
+				return nil, fmt.Errorf("ERROR: The column %v can't be updated on duplicate because it isn't inserted", u)
+			}
+		}
+		update = b.updateColumns
+		if b.audit != nil && b.audit.UpdatedAt != "" && !contains(update, b.audit.UpdatedAt) {
+			update = append(update[:len(update):len(update)], b.audit.UpdatedAt)
+		}
+	}
+	if len(b.updateExprs) > 0 || b.audit != nil {
+		// A column set with an expression isn't overwritten with the inserted value, neither are the
+		// audit columns of the insert
+		filtered := make([]string, 0, len(update))
+		for _, u := range update {
+			if _, ok := b.updateExprs[u]; !ok && !b.keptOnUpdate(u) {
+				filtered = append(filtered, u)
+			}
+		}
+		update = filtered
+	}
+	if len(update) == 0 && len(b.updateExprs) == 0 {
+		return nil, errNoUpdate
+	}
+	if err := validateIdentifiers(b.conflictTarget); err != nil {
+		return nil, err
+	}
+	// The expressions are written as given, only their columns are quoted
+	set := make(map[string]string, len(b.updateExprs))
+	for column, expr := range b.updateExprs {
+		if err := validateIdentifier(column); err != nil {
+			return nil, err
+		}
+		set[b.quote(column)] = expr
+	}
+	return &Conflict{
+		Action:      DoUpdate,
+		Target:      b.quoteAll(b.conflictTarget),
+		Update:      b.quoteAll(update),
+		Set:         set,
+		TargetWhere: b.conflictWhere,
+		UpdateWhere: b.updateWhere,
+		Columns:     b.quoteAll(columns),
+	}, nil
+}
+
+// contains reports whether s is in list.
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
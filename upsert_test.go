@@ -0,0 +1,226 @@
+package bulk
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// buildSQL returns the SQL of the statements built by b with replaceOnDuplicate.
+func buildSQL(b *Bulk, replaceOnDuplicate bool) ([]string, error) {
+	statements, err := b.BuildStatements(replaceOnDuplicate)
+	if err != nil {
+		return nil, err
+	}
+	sql := make([]string, len(statements))
+	for i, s := range statements {
+		sql[i] = s.SQL
+	}
+	return sql, nil
+}
+
+// checkError fails t unless err contains want, or is nil if want is empty.
+func checkError(t *testing.T, err error, want string) bool {
+	t.Helper()
+	if want == "" {
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return true
+	}
+	if err == nil || !strings.Contains(err.Error(), want) {
+		t.Fatalf("got the error %v, expected one containing %q", err, want)
+	}
+	return false
+}
+
+func TestUpsert(t *testing.T) {
+	tests := []struct {
+		name    string
+		dialect Dialect
+		setup   func(b *Bulk)
+		replace bool
+		want    string
+		wantErr string
+	}{
+		{
+			name:    "mysql",
+			dialect: MySQL,
+			replace: true,
+			want:    "INSERT INTO `t`(`id`, `name`) VALUES (?,?),(?,?) ON DUPLICATE KEY UPDATE `id`=VALUES(`id`),`name`=VALUES(`name`)",
+		},
+		{
+			name:    "mysql update columns",
+			dialect: MySQL,
+			setup:   func(b *Bulk) { b.OnDuplicateUpdate("name") },
+			replace: true,
+			want:    "INSERT INTO `t`(`id`, `name`) VALUES (?,?),(?,?) ON DUPLICATE KEY UPDATE `name`=VALUES(`name`)",
+		},
+		{
+			name:    "mysql update expression",
+			dialect: MySQL,
+			setup: func(b *Bulk) {
+				b.OnDuplicateUpdate("name")
+				b.OnDuplicateUpdateExpr(map[string]string{"n": "n + 1"})
+			},
+			replace: true,
+			want:    "INSERT INTO `t`(`id`, `name`) VALUES (?,?),(?,?) ON DUPLICATE KEY UPDATE `name`=VALUES(`name`),`n`=n + 1",
+		},
+		{
+			name:    "mysql ignore",
+			dialect: MySQL,
+			setup:   func(b *Bulk) { b.SetIgnoreDuplicates(true) },
+			want:    "INSERT IGNORE INTO `t`(`id`, `name`) VALUES (?,?),(?,?)",
+		},
+		{
+			name:    "mysql replace into",
+			dialect: MySQL,
+			setup:   func(b *Bulk) { b.SetReplaceInto(true) },
+			want:    "REPLACE INTO `t`(`id`, `name`) VALUES (?,?),(?,?)",
+		},
+		{
+			name:    "mysql update condition",
+			dialect: MySQL,
+			setup:   func(b *Bulk) { b.OnDuplicateUpdateWhere("t.name <> 'x'") },
+			replace: true,
+			wantErr: "MySQL doesn't support conditions",
+		},
+		{
+			name:    "mysql8 row alias",
+			dialect: MySQL8,
+			setup:   func(b *Bulk) { b.OnDuplicateUpdate("name") },
+			replace: true,
+			want:    "INSERT INTO `t`(`id`, `name`) VALUES (?,?),(?,?) AS `new` ON DUPLICATE KEY UPDATE `name`=`new`.`name`",
+		},
+		{
+			name:    "postgres",
+			dialect: Postgres,
+			setup:   func(b *Bulk) { b.SetConflictTarget("id") },
+			replace: true,
+			want:    `INSERT INTO "t"("id", "name") VALUES ($1,$2),($3,$4) ON CONFLICT ("id") DO UPDATE SET "id"=EXCLUDED."id","name"=EXCLUDED."name"`,
+		},
+		{
+			name:    "postgres update condition",
+			dialect: Postgres,
+			setup: func(b *Bulk) {
+				b.SetConflictTarget("id")
+				b.OnDuplicateUpdate("name")
+				b.OnDuplicateUpdateWhere("t.name <> 'x'")
+			},
+			replace: true,
+			want:    `INSERT INTO "t"("id", "name") VALUES ($1,$2),($3,$4) ON CONFLICT ("id") DO UPDATE SET "name"=EXCLUDED."name" WHERE t.name <> 'x'`,
+		},
+		{
+			name:    "postgres ignore",
+			dialect: Postgres,
+			setup:   func(b *Bulk) { b.SetConflictTarget("id"); b.SetIgnoreDuplicates(true) },
+			want:    `INSERT INTO "t"("id", "name") VALUES ($1,$2),($3,$4) ON CONFLICT ("id") DO NOTHING`,
+		},
+		{
+			name:    "postgres without target",
+			dialect: Postgres,
+			replace: true,
+			wantErr: "require a conflict target",
+		},
+		{
+			name:    "postgres replace into",
+			dialect: Postgres,
+			setup:   func(b *Bulk) { b.SetReplaceInto(true) },
+			wantErr: "doesn't support REPLACE INTO",
+		},
+		{
+			name:    "postgres invalid target",
+			dialect: Postgres,
+			setup:   func(b *Bulk) { b.SetConflictTarget("id;") },
+			replace: true,
+			wantErr: `Invalid identifier "id;"`,
+		},
+		{
+			name:    "sqlite",
+			dialect: SQLite,
+			setup:   func(b *Bulk) { b.SetConflictTarget("id"); b.OnDuplicateUpdate("name") },
+			replace: true,
+			want:    `INSERT INTO "t"("id", "name") VALUES (?,?),(?,?) ON CONFLICT ("id") DO UPDATE SET "name"=excluded."name"`,
+		},
+		{
+			name:    "cockroach upsert",
+			dialect: CockroachDB,
+			replace: true,
+			want:    `UPSERT INTO "t"("id", "name") VALUES ($1,$2),($3,$4)`,
+		},
+		{
+			name:    "cockroach partial update",
+			dialect: CockroachDB,
+			setup:   func(b *Bulk) { b.SetConflictTarget("id"); b.OnDuplicateUpdate("name") },
+			replace: true,
+			want:    `INSERT INTO "t"("id", "name") VALUES ($1,$2),($3,$4) ON CONFLICT ("id") DO UPDATE SET "name"=EXCLUDED."name"`,
+		},
+		{
+			name:    "cockroach partial update without target",
+			dialect: CockroachDB,
+			setup:   func(b *Bulk) { b.OnDuplicateUpdate("name") },
+			replace: true,
+			wantErr: "require a conflict target",
+		},
+		{
+			name:    "update of a column which isn't inserted",
+			dialect: Postgres,
+			setup:   func(b *Bulk) { b.SetConflictTarget("id"); b.OnDuplicateUpdate("other") },
+			replace: true,
+			wantErr: "isn't inserted",
+		},
+		{
+			name:    "ignore and replace",
+			dialect: Postgres,
+			setup:   func(b *Bulk) { b.SetConflictTarget("id"); b.SetIgnoreDuplicates(true) },
+			replace: true,
+			wantErr: "can't be both ignored and replaced",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := New(tt.dialect)
+			b.Init("t", "id", "name")
+			if tt.setup != nil {
+				tt.setup(b)
+			}
+			b.PrepareValues(1, "a")
+			b.PrepareValues(2, "b")
+			got, err := buildSQL(b, tt.replace)
+			if !checkError(t, err, tt.wantErr) {
+				return
+			}
+			if want := []string{tt.want}; !reflect.DeepEqual(got, want) {
+				t.Errorf("got %q, expected %q", got, want)
+			}
+		})
+	}
+}
+
+func TestUpsertWithoutUpdate(t *testing.T) {
+	b := New(Postgres)
+	b.SetAudit(&Audit{CreatedAt: "created_at"})
+	b.Init("t", "id")
+	b.SetConflictTarget("id")
+	b.OnDuplicateUpdate("created_at")
+	b.PrepareValues(1)
+	if _, err := b.BuildStatements(true); err != errNoUpdate {
+		t.Errorf("got the error %v, expected %v", err, errNoUpdate)
+	}
+}
+
+func TestUpsertArgs(t *testing.T) {
+	b := New(Postgres)
+	b.Init("t", "id", "name")
+	b.SetConflictTarget("id")
+	b.PrepareValues(1, "a")
+	b.PrepareValues(2, "b")
+	statements, err := b.BuildStatements(true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []interface{}{1, "a", 2, "b"}
+	if len(statements) != 1 || !reflect.DeepEqual(statements[0].Args, want) {
+		t.Errorf("got %v, expected one statement with the arguments %v", statements, want)
+	}
+}
@@ -0,0 +1,212 @@
+package bulk
+
+import (
+	"crypto/rand"
+	"database/sql/driver"
+	"encoding/hex"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// BinaryUUIDs is implemented by the dialects of the databases which have no UUID type, such as MySQL and
+// Oracle, whose UUID columns are usually BINARY(16) or RAW(16). Their UUIDs are sent as 16 bytes, and
+// those of the other dialects as text, e.g. "f47ac10b-58cc-4372-a567-0e02b2c3d479".
+type BinaryUUIDs interface {
+	BinaryUUIDs()
+}
+
+func (mysqlDialect) BinaryUUIDs()  {}
+func (oracleDialect) BinaryUUIDs() {}
+
+// UUIDFormat is how the UUIDs of a column are sent to the database.
+type UUIDFormat int
+
+const (
+	UUIDDefault UUIDFormat = iota // Binary for the dialects implementing BinaryUUIDs, text for the others
+	UUIDText                      // Text such as "f47ac10b-58cc-4372-a567-0e02b2c3d479", e.g. for CHAR(36)
+	UUIDBinary                    // 16 bytes, e.g. for BINARY(16)
+)
+
+// uuidColumn is the UUID handling of a column set with SetUUID.
+type uuidColumn struct {
+	format   UUIDFormat
+	generate bool
+}
+
+// SetUUID makes column a UUID column, whose values are sent in format. Its values may be a [16]byte of
+// any type, such as uuid.UUID of github.com/google/uuid, a string or a []byte holding the text of a UUID,
+// or 16 bytes. The nil values are replaced with a new random UUID (version 4) if generate is true, and sent
+// as NULL otherwise. The [16]byte values of the other columns are sent in the default format of the
+// dialect, unless their type is a driver.Valuer.
+func (b *Bulk) SetUUID(column string, format UUIDFormat, generate bool) {
+	if b.uuids == nil {
+		b.uuids = map[string]uuidColumn{}
+	}
+	b.uuids[column] = uuidColumn{format: format, generate: generate}
+	b.columnUUIDs = nil
+}
+
+// uuidColumns returns the UUID handling of every column by position, nil for the columns without one.
+func (b *Bulk) uuidColumns() []*uuidColumn {
+	if len(b.columnUUIDs) != len(b.columns) {
+		b.columnUUIDs = make([]*uuidColumn, len(b.columns))
+		for i, c := range b.columns {
+			if u, ok := b.uuids[c]; ok {
+				b.columnUUIDs[i] = &u
+			}
+		}
+	}
+	return b.columnUUIDs
+}
+
+// normalizeUUIDs encodes the UUIDs of row, which is in the buffer and whose NULL values are explicit, and
+// generates those of the nil values of the columns set with SetUUID to generate them.
+func (b *Bulk) normalizeUUIDs(row []interface{}) error {
+	var columns []*uuidColumn
+	if len(b.uuids) > 0 {
+		columns = b.uuidColumns()
+	}
+	for i, v := range row {
+		var u *uuidColumn
+		if i < len(columns) {
+			u = columns[i]
+		}
+		if u == nil {
+			// Only the [16]byte values the drivers reject are encoded
+			if id, ok := uuidArray(v); ok {
+				if _, ok := v.(driver.Valuer); !ok {
+					row[i] = b.encodeUUID(id, UUIDDefault)
+				}
+			}
+			continue
+		}
+		if _, ok := uuidArray(v); !ok {
+			// The Valuers such as uuid.NullUUID may hold a NULL
+			if valuer, ok := v.(driver.Valuer); ok {
+				dv, err := valuer.Value()
+				if err != nil {
+					return fmt.Errorf("ERROR: The value %v of the column %v isn't a UUID: %v", v, b.columns[i], err)
+				}
+				v, row[i] = dv, dv
+			}
+		}
+		if v == nil {
+			if u.generate {
+				id, err := newUUID()
+				if err != nil {
+					return err
+				}
+				row[i] = b.encodeUUID(id, u.format)
+			}
+			continue
+		}
+		if _, ok := v.(Raw); ok || v == Missing {
+			continue
+		}
+		id, err := parseUUID(v)
+		if err != nil {
+			return fmt.Errorf("ERROR: The value %v of the column %v isn't a UUID: %v", v, b.columns[i], err)
+		}
+		row[i] = b.encodeUUID(id, u.format)
+	}
+	return nil
+}
+
+// encodeUUID returns id in format.
+func (b *Bulk) encodeUUID(id [16]byte, format UUIDFormat) interface{} {
+	if format == UUIDDefault {
+		format = UUIDText
+		if _, ok := b.dialect.(BinaryUUIDs); ok {
+			format = UUIDBinary
+		}
+	}
+	if format == UUIDBinary {
+		return id[:]
+	}
+	return formatUUID(id)
+}
+
+// uuidArray returns v as a [16]byte if its type is an array of 16 bytes.
+func uuidArray(v interface{}) ([16]byte, bool) {
+	var id [16]byte
+	if a, ok := v.([16]byte); ok {
+		return a, true
+	}
+	t := reflect.TypeOf(v)
+	if t == nil || t.Kind() != reflect.Array || t.Len() != 16 || t.Elem().Kind() != reflect.Uint8 {
+		return id, false
+	}
+	rv := reflect.ValueOf(v)
+	for i := range id {
+		id[i] = byte(rv.Index(i).Uint())
+	}
+	return id, true
+}
+
+// parseUUID returns the UUID held by v, which isn't a driver.Valuer unless it's a [16]byte.
+func parseUUID(v interface{}) ([16]byte, error) {
+	if id, ok := uuidArray(v); ok {
+		return id, nil
+	}
+	switch v := v.(type) {
+	case string:
+		return parseUUIDText(v)
+	case []byte:
+		if len(v) == 16 {
+			var id [16]byte
+			copy(id[:], v)
+			return id, nil
+		}
+		return parseUUIDText(string(v))
+	}
+	return [16]byte{}, fmt.Errorf("unsupported type %T", v)
+}
+
+// parseUUIDText parses the text of a UUID, with or without hyphens, braces or a "urn:uuid:" prefix.
+func parseUUIDText(s string) ([16]byte, error) {
+	var id [16]byte
+	t := strings.TrimPrefix(strings.ToLower(s), "urn:uuid:")
+	if len(t) == 38 && t[0] == '{' && t[37] == '}' {
+		t = t[1:37]
+	}
+	if len(t) == 36 {
+		if t[8] != '-' || t[13] != '-' || t[18] != '-' || t[23] != '-' {
+			return id, fmt.Errorf("invalid UUID %q", s)
+		}
+		t = t[:8] + t[9:13] + t[14:18] + t[19:23] + t[24:]
+	}
+	if len(t) != 32 {
+		return id, fmt.Errorf("invalid UUID %q", s)
+	}
+	if _, err := hex.Decode(id[:], []byte(t)); err != nil {
+		return id, fmt.Errorf("invalid UUID %q", s)
+	}
+	return id, nil
+}
+
+// formatUUID returns the canonical text of id.
+func formatUUID(id [16]byte) string {
+	var buf [36]byte
+	hex.Encode(buf[0:8], id[0:4])
+	buf[8] = '-'
+	hex.Encode(buf[9:13], id[4:6])
+	buf[13] = '-'
+	hex.Encode(buf[14:18], id[6:8])
+	buf[18] = '-'
+	hex.Encode(buf[19:23], id[8:10])
+	buf[23] = '-'
+	hex.Encode(buf[24:], id[10:])
+	return string(buf[:])
+}
+
+// newUUID returns a new random UUID, version 4 of RFC 9562.
+func newUUID() ([16]byte, error) {
+	var id [16]byte
+	if _, err := rand.Read(id[:]); err != nil {
+		return id, fmt.Errorf("ERROR: Can't generate a UUID: %v", err)
+	}
+	id[6] = id[6]&0x0f | 0x40
+	id[8] = id[8]&0x3f | 0x80
+	return id, nil
+}
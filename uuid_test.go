@@ -0,0 +1,105 @@
+package bulk
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"testing"
+)
+
+// nullUUID is a driver.Valuer holding a UUID or NULL, as uuid.NullUUID of github.com/google/uuid does.
+type nullUUID struct {
+	uuid  [16]byte
+	valid bool
+}
+
+func (n nullUUID) Value() (driver.Value, error) {
+	if !n.valid {
+		return nil, nil
+	}
+	return formatUUID(n.uuid), nil
+}
+
+func TestUUID(t *testing.T) {
+	text := "f47ac10b-58cc-4372-a567-0e02b2c3d479"
+	id := [16]byte{0xf4, 0x7a, 0xc1, 0x0b, 0x58, 0xcc, 0x43, 0x72, 0xa5, 0x67, 0x0e, 0x02, 0xb2, 0xc3, 0xd4, 0x79}
+	type myUUID [16]byte
+	tests := []struct {
+		name    string
+		dialect Dialect
+		format  UUIDFormat
+		value   interface{}
+		want    interface{}
+		wantErr string
+	}{
+		{name: "array as text", dialect: Postgres, value: id, want: text},
+		{name: "named array as text", dialect: Postgres, value: myUUID(id), want: text},
+		{name: "array as binary", dialect: MySQL, value: id, want: id[:]},
+		{name: "text as binary", dialect: MySQL, value: text, want: id[:]},
+		{name: "bytes as text", dialect: Postgres, value: id[:], want: text},
+		{name: "braces", dialect: Postgres, value: "{" + text + "}", want: text},
+		{name: "urn", dialect: Postgres, value: "urn:uuid:" + text, want: text},
+		{name: "upper case without hyphens", dialect: Postgres, value: "F47AC10B58CC4372A5670E02B2C3D479", want: text},
+		{name: "text format", dialect: MySQL, format: UUIDText, value: id, want: text},
+		{name: "binary format", dialect: Postgres, format: UUIDBinary, value: text, want: id[:]},
+		{name: "valuer", dialect: MySQL, value: nullUUID{uuid: id, valid: true}, want: id[:]},
+		{name: "null valuer", dialect: MySQL, value: nullUUID{}, want: nil},
+		{name: "nil", dialect: Postgres, value: nil, want: nil},
+		{name: "invalid text", dialect: Postgres, value: "f47ac10b-58cc", wantErr: "isn't a UUID"},
+		{name: "misplaced hyphens", dialect: Postgres, value: "f47ac10b5-8cc-4372-a567-0e02b2c3d479", wantErr: "isn't a UUID"},
+		{name: "unsupported type", dialect: Postgres, value: 42, wantErr: "unsupported type int"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := New(tt.dialect)
+			b.Init("t", "id")
+			b.SetUUID("id", tt.format, false)
+			err := b.PrepareValues(tt.value)
+			if !checkError(t, err, tt.wantErr) {
+				return
+			}
+			statements, err := b.BuildStatements(false)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := statements[0].Args[0]
+			if want, ok := tt.want.([]byte); ok {
+				if g, ok := got.([]byte); !ok || !bytes.Equal(g, want) {
+					t.Errorf("got %#v, expected %#v", got, want)
+				}
+			} else if got != tt.want {
+				t.Errorf("got %#v, expected %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUUIDGenerate(t *testing.T) {
+	b := New(Postgres)
+	b.Init("t", "id", "other")
+	b.SetUUID("id", UUIDDefault, true)
+	b.PrepareValues(nil, nil)
+	b.PrepareValues(nil, nil)
+	statements, err := b.BuildStatements(false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	args := statements[0].Args
+	seen := map[interface{}]bool{}
+	for _, i := range []int{0, 2} {
+		id, err := parseUUIDText(args[i].(string))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if id[6]>>4 != 4 || id[8]>>6 != 2 {
+			t.Errorf("%v isn't a version 4 UUID", args[i])
+		}
+		seen[args[i]] = true
+	}
+	if len(seen) != 2 {
+		t.Errorf("the generated UUIDs %v aren't unique", args)
+	}
+	// The columns which aren't set with SetUUID keep their NULL values
+	if args[1] != nil || args[3] != nil {
+		t.Errorf("got %v, expected NULL values for the other column", args)
+	}
+}
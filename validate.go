@@ -0,0 +1,98 @@
+package bulk
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Validator checks a value of a column before it's buffered, returning why it isn't valid.
+type Validator func(v interface{}) error
+
+// RowValidator checks the values of a row before it's buffered, returning why they aren't valid.
+type RowValidator func(vals []interface{}) error
+
+// ValidationError holds every validation failure of a row.
+type ValidationError struct {
+	Errors []error // Failures of the column validators, in column order, followed by the one of the row validator
+}
+
+func (e *ValidationError) Error() string {
+	msgs := make([]string, len(e.Errors))
+	for i, err := range e.Errors {
+		msgs[i] = err.Error()
+	}
+	return strings.Join(msgs, "; ")
+}
+
+func (e *ValidationError) Unwrap() []error {
+	return e.Errors
+}
+
+// SetValidator makes PrepareValues, and the methods built on it, check the values of column, one of the
+// columns given to Init, with v. A nil v removes the validator of the column.
+func (b *Bulk) SetValidator(column string, v Validator) {
+	if b.validators == nil {
+		b.validators = map[string]Validator{}
+	}
+	if v == nil {
+		delete(b.validators, column)
+	} else {
+		b.validators[column] = v
+	}
+	b.columnValidators = nil
+}
+
+// SetRowValidator makes PrepareValues, and the methods built on it, check the values of each row with fn,
+// after the column validators, e.g. to compare two columns. It receives the values of the columns given to
+// Init. A nil fn removes the row validator.
+func (b *Bulk) SetRowValidator(fn RowValidator) {
+	b.rowValidator = fn
+}
+
+// SetLenientValidation makes the rows which fail the validation be skipped and reported by Rejected. By
+// default, PrepareValues returns a *RowError wrapping the *ValidationError of the row, which isn't
+// buffered, and a load reading a file stops.
+func (b *Bulk) SetLenientValidation(lenient bool) {
+	b.lenient = lenient
+}
+
+// validate checks vals with the string guards and the validators, returning a *ValidationError with all
+// their failures.
+func (b *Bulk) validate(vals []interface{}) error {
+	if len(b.validators) == 0 && b.rowValidator == nil && len(b.guards) == 0 {
+		return nil
+	}
+	columns := b.inputColumns()
+	if len(b.validators) > 0 && len(b.columnValidators) != len(columns) {
+		b.columnValidators = make([]Validator, len(columns))
+		for i, c := range columns {
+			b.columnValidators[i] = b.validators[c]
+		}
+	}
+	errs := b.checkStrings(vals)
+	for i, v := range b.columnValidators {
+		if v == nil || vals[i] == Missing {
+			continue
+		}
+		if err := v(vals[i]); err != nil {
+			errs = append(errs, fmt.Errorf("column %v: %w", columns[i], err))
+		}
+	}
+	if b.rowValidator != nil {
+		if err := b.rowValidator(vals); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	return &ValidationError{Errors: errs}
+}
+
+// NotNull is a Validator rejecting the NULL values: nil, nil pointers and invalid sql.Null* values.
+func NotNull(v interface{}) error {
+	if nullValue(v) == nil {
+		return fmt.Errorf("the value is NULL")
+	}
+	return nil
+}